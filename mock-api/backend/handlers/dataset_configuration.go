@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// readDatasetUpload obtiene el contenido y el formato ("csv" o "json") de un dataset subido.
+// El contenido puede enviarse como body de la solicitud o como archivo multipart en el campo 'file'.
+// El formato se toma del query param 'format', de la extensión del archivo o del Content-Type.
+func readDatasetUpload(c *fiber.Ctx) ([]byte, string, error) {
+	data := c.Body()
+	format := strings.ToLower(c.Query("format"))
+	contentType := strings.ToLower(c.Get("Content-Type"))
+
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", fmt.Errorf("se esperaba un archivo en el campo 'file': %w", err)
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+		if data, err = io.ReadAll(file); err != nil {
+			return nil, "", err
+		}
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+		}
+		contentType = fileHeader.Header.Get("Content-Type")
+	}
+
+	if format == "" {
+		format = "json"
+		if strings.Contains(contentType, "csv") {
+			format = "csv"
+		}
+	}
+	if format != "csv" && format != "json" {
+		return nil, "", fmt.Errorf("formato '%s' no soportado. Formatos válidos: csv, json", format)
+	}
+	return data, format, nil
+}
+
+// parseCSVDataset convierte un CSV con fila de encabezados en una lista de registros.
+// Todos los valores se conservan como texto.
+func parseCSVDataset(data []byte) ([]map[string]interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("el CSV debe incluir una fila de encabezados")
+	}
+
+	header := rows[0]
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "" {
+			return nil, fmt.Errorf("la columna %d no tiene nombre en la fila de encabezados", i+1)
+		}
+		// Con nombres repetidos la última columna reemplazaría en silencio el valor de la anterior
+		if first, ok := columns[header[i]]; ok {
+			return nil, fmt.Errorf("las columnas %d y %d tienen el mismo nombre '%s'", first+1, i+1, header[i])
+		}
+		columns[header[i]] = i
+	}
+
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			record[name] = row[i]
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseJSONDataset convierte una lista JSON de objetos en una lista de registros.
+func parseJSONDataset(data []byte) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("se esperaba una lista JSON de objetos: %w", err)
+	}
+	for i, record := range records {
+		if record == nil {
+			return nil, fmt.Errorf("el elemento %d no es un objeto JSON", i)
+		}
+	}
+	return records, nil
+}
+
+// UploadDataset maneja las solicitudes POST y PUT /configure-mock/datasets/:name
+func UploadDataset(c *fiber.Ctx) error {
+	name := strings.Clone(c.Params("name"))
+	if !partialNameRegex.MatchString(name) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El nombre del dataset es inválido. Debe iniciar con una letra y contener solo letras, números, '.', '-' o '_'."})
+	}
+
+	data, format, err := readDatasetUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo leer el dataset", "details": err.Error()})
+	}
+
+	var records []map[string]interface{}
+	if format == "csv" {
+		records, err = parseCSVDataset(data)
+	} else {
+		records, err = parseJSONDataset(data)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear el dataset", "format": format, "details": err.Error()})
+	}
+
+	_, existed := storage.GetDataset(name)
+	if err := storage.SaveDataset(name, records); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar el dataset en el almacenamiento persistente.", "details": err.Error()})
+	}
+
+	status := fiber.StatusCreated
+	if existed {
+		status = fiber.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{"message": "Dataset guardado exitosamente", "name": name, "format": format, "records": len(records)})
+}
+
+// GetDatasets maneja la solicitud GET /configure-mock/datasets
+func GetDatasets(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(storage.GetAllDatasets())
+}
+
+// GetDataset maneja la solicitud GET /configure-mock/datasets/:name
+func GetDataset(c *fiber.Ctx) error {
+	records, ok := storage.GetDataset(c.Params("name"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Dataset no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(records)
+}
+
+// DeleteDataset maneja la solicitud DELETE /configure-mock/datasets/:name
+func DeleteDataset(c *fiber.Ctx) error {
+	if !storage.DeleteDataset(c.Params("name")) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Dataset no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Dataset eliminado exitosamente"})
+}
@@ -0,0 +1,795 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Motor de plantillas compatible con Handlebars para mocks portados desde WireMock.
+// Soporta:
+//   - Expresiones con escape HTML '{{request.query.name}}' y sin escape '{{{request.body}}}'
+//   - Rutas con puntos, 'this', '../' y variables '@index', '@key', '@first', '@last'
+//   - Helpers de la biblioteca compartida con argumentos y subexpresiones: '{{upper (default "x" request.query.name)}}'
+//   - Bloques '#if', '#unless', '#each' y '#with' con '{{else}}'
+//   - Partials '{{> nombre}}' registrados con el motor handlebars ('engine': 'handlebars')
+//   - Comentarios '{{! ... }}' / '{{!-- ... --}}' y control de espacios con '~'
+
+// maxHandlebarsPartialDepth limita la recursión de partials.
+const maxHandlebarsPartialDepth = 16
+
+// hbsNodeType identifica el tipo de nodo del árbol sintáctico.
+type hbsNodeType int
+
+const (
+	hbsText hbsNodeType = iota
+	hbsMustache
+	hbsBlock
+	hbsPartial
+)
+
+// hbsNode es un nodo del árbol sintáctico de una plantilla Handlebars.
+type hbsNode struct {
+	kind    hbsNodeType
+	text    string     // Texto literal o nombre del bloque/partial
+	expr    *hbsExpr   // Expresión de un mustache, o parámetros de un bloque/partial
+	escape  bool       // true para '{{ }}', false para '{{{ }}}'
+	body    []*hbsNode // Contenido del bloque
+	inverse []*hbsNode // Contenido después de '{{else}}'
+}
+
+// hbsExpr es una expresión: un nombre (ruta o helper) seguido de parámetros.
+type hbsExpr struct {
+	name   *hbsArg
+	params []*hbsArg
+}
+
+// hbsArg es un argumento: literal, ruta o subexpresión.
+type hbsArg struct {
+	literal interface{}
+	isLit   bool
+	path    string
+	sub     *hbsExpr
+}
+
+// hbsTag es una etiqueta '{{...}}' extraída del texto.
+type hbsTag struct {
+	content    string
+	raw        bool // '{{{ }}}'
+	stripLeft  bool // '{{~'
+	stripRight bool // '~}}'
+}
+
+// --- Análisis ---
+
+// parseHandlebars convierte el texto de la plantilla en un árbol sintáctico.
+func parseHandlebars(src string) ([]*hbsNode, error) {
+	items, err := tokenizeHandlebars(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &hbsParser{items: items}
+	nodes, closing, err := p.parseNodes()
+	if err != nil {
+		return nil, err
+	}
+	if closing != "" {
+		return nil, fmt.Errorf("cierre inesperado '{{%s}}'", closing)
+	}
+	return nodes, nil
+}
+
+// tokenizeHandlebars separa el texto en literales (string) y etiquetas (hbsTag), aplicando el control de espacios '~'.
+func tokenizeHandlebars(src string) ([]interface{}, error) {
+	var items []interface{}
+	for len(src) > 0 {
+		start := strings.Index(src, "{{")
+		if start < 0 {
+			items = append(items, src)
+			break
+		}
+		if start > 0 {
+			items = append(items, src[:start])
+		}
+		src = src[start:]
+
+		tag := hbsTag{}
+		var end int
+		switch {
+		case strings.HasPrefix(src, "{{!--"):
+			end = strings.Index(src, "--}}")
+			if end < 0 {
+				return nil, fmt.Errorf("comentario sin cerrar")
+			}
+			src = src[end+4:]
+			continue
+		case strings.HasPrefix(src, "{{{") || strings.HasPrefix(src, "{{~{"):
+			tag.raw = true
+			end = strings.Index(src, "}}}")
+			if end < 0 {
+				return nil, fmt.Errorf("expresión '{{{' sin cerrar")
+			}
+			tag.content = src[3:end]
+			if strings.HasPrefix(src, "{{~{") {
+				tag.content = src[4:end]
+				tag.stripLeft = true
+			}
+			end += 3
+		default:
+			end = strings.Index(src, "}}")
+			if end < 0 {
+				return nil, fmt.Errorf("expresión '{{' sin cerrar")
+			}
+			tag.content = src[2:end]
+			end += 2
+		}
+
+		if strings.HasPrefix(tag.content, "~") {
+			tag.stripLeft = true
+			tag.content = tag.content[1:]
+		}
+		if strings.HasSuffix(tag.content, "~") {
+			tag.stripRight = true
+			tag.content = tag.content[:len(tag.content)-1]
+		}
+		tag.content = strings.TrimSpace(tag.content)
+		src = src[end:]
+
+		// Los comentarios simples se descartan
+		if strings.HasPrefix(tag.content, "!") {
+			continue
+		}
+
+		// Control de espacios: '~' elimina los espacios del literal adyacente
+		if tag.stripLeft && len(items) > 0 {
+			if text, ok := items[len(items)-1].(string); ok {
+				items[len(items)-1] = strings.TrimRightFunc(text, unicode.IsSpace)
+			}
+		}
+		if tag.stripRight {
+			src = strings.TrimLeftFunc(src, unicode.IsSpace)
+		}
+		items = append(items, tag)
+	}
+	return items, nil
+}
+
+// hbsParser construye el árbol a partir de los literales y etiquetas.
+type hbsParser struct {
+	items []interface{}
+	pos   int
+}
+
+// parseNodes lee nodos hasta encontrar '{{else}}' o un cierre '{{/...}}', que se devuelve como 'closing'.
+func (p *hbsParser) parseNodes() (nodes []*hbsNode, closing string, err error) {
+	for p.pos < len(p.items) {
+		item := p.items[p.pos]
+		p.pos++
+
+		text, isText := item.(string)
+		if isText {
+			nodes = append(nodes, &hbsNode{kind: hbsText, text: text})
+			continue
+		}
+
+		tag := item.(hbsTag)
+		switch {
+		case tag.raw:
+			expr, err := parseHandlebarsExpr(tag.content)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, &hbsNode{kind: hbsMustache, expr: expr})
+
+		case tag.content == "else" || tag.content == "^":
+			return nodes, "else", nil
+
+		case strings.HasPrefix(tag.content, "/"):
+			return nodes, "/" + strings.TrimSpace(tag.content[1:]), nil
+
+		case strings.HasPrefix(tag.content, "#"):
+			expr, err := parseHandlebarsExpr(strings.TrimSpace(tag.content[1:]))
+			if err != nil {
+				return nil, "", err
+			}
+			if expr.name == nil || expr.name.path == "" {
+				return nil, "", fmt.Errorf("nombre de bloque inválido en '{{%s}}'", tag.content)
+			}
+			name := expr.name.path
+			block := &hbsNode{kind: hbsBlock, text: name, expr: expr}
+
+			body, end, err := p.parseNodes()
+			if err != nil {
+				return nil, "", err
+			}
+			block.body = body
+			if end == "else" {
+				inverse, end2, err := p.parseNodes()
+				if err != nil {
+					return nil, "", err
+				}
+				block.inverse = inverse
+				end = end2
+			}
+			if end != "/"+name {
+				return nil, "", fmt.Errorf("el bloque '{{#%s}}' no está cerrado correctamente", name)
+			}
+			nodes = append(nodes, block)
+
+		case strings.HasPrefix(tag.content, ">"):
+			expr, err := parseHandlebarsExpr(strings.TrimSpace(tag.content[1:]))
+			if err != nil {
+				return nil, "", err
+			}
+			if expr.name == nil {
+				return nil, "", fmt.Errorf("nombre de partial inválido en '{{%s}}'", tag.content)
+			}
+			name := expr.name.path
+			if expr.name.isLit {
+				name = toString(expr.name.literal)
+			}
+			nodes = append(nodes, &hbsNode{kind: hbsPartial, text: name, expr: expr})
+
+		default:
+			expr, err := parseHandlebarsExpr(tag.content)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, &hbsNode{kind: hbsMustache, expr: expr, escape: true})
+		}
+	}
+	return nodes, "", nil
+}
+
+// parseHandlebarsExpr analiza el contenido de una etiqueta: 'nombre arg1 "literal" (sub expr)'.
+func parseHandlebarsExpr(src string) (*hbsExpr, error) {
+	args, rest, err := parseHandlebarsArgs(src)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("paréntesis de cierre inesperado en '%s'", src)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expresión vacía")
+	}
+	return &hbsExpr{name: args[0], params: args[1:]}, nil
+}
+
+// parseHandlebarsArgs lee argumentos hasta el final o hasta un ')' sin pareja, devolviendo el texto restante.
+func parseHandlebarsArgs(src string) ([]*hbsArg, string, error) {
+	var args []*hbsArg
+	for {
+		src = strings.TrimLeftFunc(src, unicode.IsSpace)
+		if src == "" || src[0] == ')' {
+			return args, src, nil
+		}
+
+		switch c := src[0]; {
+		case c == '(':
+			inner, rest, err := parseHandlebarsArgs(src[1:])
+			if err != nil {
+				return nil, "", err
+			}
+			if !strings.HasPrefix(rest, ")") {
+				return nil, "", fmt.Errorf("subexpresión sin cerrar")
+			}
+			if len(inner) == 0 {
+				return nil, "", fmt.Errorf("subexpresión vacía")
+			}
+			args = append(args, &hbsArg{sub: &hbsExpr{name: inner[0], params: inner[1:]}})
+			src = rest[1:]
+
+		case c == '"' || c == '\'':
+			end := 1
+			for end < len(src) && (src[end] != c || src[end-1] == '\\') {
+				end++
+			}
+			if end >= len(src) {
+				return nil, "", fmt.Errorf("cadena sin cerrar")
+			}
+			lit := strings.ReplaceAll(src[1:end], `\`+string(c), string(c))
+			args = append(args, &hbsArg{literal: lit, isLit: true})
+			src = src[end+1:]
+
+		default:
+			end := strings.IndexFunc(src, func(r rune) bool { return unicode.IsSpace(r) || r == '(' || r == ')' })
+			if end < 0 {
+				end = len(src)
+			}
+			word := src[:end]
+			src = src[end:]
+
+			if strings.Contains(word, "=") {
+				return nil, "", fmt.Errorf("los argumentos con nombre ('%s') no están soportados", word)
+			}
+			switch word {
+			case "true":
+				args = append(args, &hbsArg{literal: true, isLit: true})
+			case "false":
+				args = append(args, &hbsArg{literal: false, isLit: true})
+			case "null", "undefined":
+				args = append(args, &hbsArg{literal: nil, isLit: true})
+			default:
+				if n, err := strconv.ParseFloat(word, 64); err == nil && (word[0] == '-' || unicode.IsDigit(rune(word[0]))) {
+					args = append(args, &hbsArg{literal: n, isLit: true})
+				} else {
+					args = append(args, &hbsArg{path: word})
+				}
+			}
+		}
+	}
+}
+
+// --- Evaluación ---
+
+// hbsFrame es un nivel del contexto: el valor de 'this' y las variables '@'.
+type hbsFrame struct {
+	this interface{}
+	data map[string]interface{}
+}
+
+// hbsRenderer ejecuta un árbol sintáctico con los helpers compartidos.
+type hbsRenderer struct {
+	funcs template.FuncMap
+	depth int
+}
+
+// renderHandlebars analiza y ejecuta una plantilla Handlebars con el contexto y los helpers indicados.
+// Un pánico durante la ejecución (por ejemplo un valor inesperado en reflect) se devuelve como error.
+func renderHandlebars(src string, funcs template.FuncMap, data interface{}) (out string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			out, err = "", fmt.Errorf("error al ejecutar la plantilla handlebars: %v", p)
+		}
+	}()
+	nodes, err := parseHandlebars(src)
+	if err != nil {
+		return "", err
+	}
+	r := &hbsRenderer{funcs: funcs}
+	var sb strings.Builder
+	if err := r.renderNodes(&sb, nodes, []hbsFrame{{this: data}}); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// renderNodes escribe la salida de una lista de nodos.
+func (r *hbsRenderer) renderNodes(sb *strings.Builder, nodes []*hbsNode, stack []hbsFrame) error {
+	for _, node := range nodes {
+		switch node.kind {
+		case hbsText:
+			sb.WriteString(node.text)
+
+		case hbsMustache:
+			v, err := r.evalExpr(node.expr, stack)
+			if err != nil {
+				return err
+			}
+			out := hbsToString(v)
+			if node.escape {
+				out = hbsEscape(out)
+			}
+			sb.WriteString(out)
+
+		case hbsBlock:
+			if err := r.renderBlock(sb, node, stack); err != nil {
+				return err
+			}
+
+		case hbsPartial:
+			if err := r.renderPartial(sb, node, stack); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderBlock ejecuta los bloques integrados '#if', '#unless', '#each' y '#with'.
+func (r *hbsRenderer) renderBlock(sb *strings.Builder, node *hbsNode, stack []hbsFrame) error {
+	if len(node.expr.params) != 1 {
+		return fmt.Errorf("el bloque '#%s' requiere exactamente un argumento", node.text)
+	}
+	value, err := r.evalArg(node.expr.params[0], stack)
+	if err != nil {
+		return err
+	}
+	current := stack[len(stack)-1]
+
+	switch node.text {
+	case "if":
+		if hbsTruthy(value) {
+			return r.renderNodes(sb, node.body, stack)
+		}
+		return r.renderNodes(sb, node.inverse, stack)
+
+	case "unless":
+		if !hbsTruthy(value) {
+			return r.renderNodes(sb, node.body, stack)
+		}
+		return r.renderNodes(sb, node.inverse, stack)
+
+	case "with":
+		if !hbsTruthy(value) {
+			return r.renderNodes(sb, node.inverse, stack)
+		}
+		return r.renderNodes(sb, node.body, append(stack, hbsFrame{this: value, data: current.data}))
+
+	case "each":
+		rv := reflect.ValueOf(value)
+		for rv.IsValid() && (rv.Kind() == reflect.Interface || rv.Kind() == reflect.Ptr) {
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() || !hbsTruthy(value) {
+			return r.renderNodes(sb, node.inverse, stack)
+		}
+
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				frame := hbsFrame{this: rv.Index(i).Interface(), data: map[string]interface{}{
+					"index": i, "first": i == 0, "last": i == rv.Len()-1,
+				}}
+				if err := r.renderNodes(sb, node.body, append(stack, frame)); err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			// Las claves se ordenan por su texto y se usan tal cual para leer el valor, sin conversiones
+			mapKeys := rv.MapKeys()
+			keys := make([]string, len(mapKeys))
+			for i, k := range mapKeys {
+				keys[i] = toString(k.Interface())
+			}
+			sort.Sort(mapKeysByText{names: keys, values: mapKeys})
+			for i, k := range keys {
+				item := rv.MapIndex(mapKeys[i])
+				frame := hbsFrame{this: item.Interface(), data: map[string]interface{}{
+					"key": k, "index": i, "first": i == 0, "last": i == len(keys)-1,
+				}}
+				if err := r.renderNodes(sb, node.body, append(stack, frame)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("'#each' requiere una lista o un objeto y se recibió %T", value)
+		}
+		return nil
+	}
+	return fmt.Errorf("bloque desconocido '#%s'", node.text)
+}
+
+// renderPartial ejecuta un partial registrado con el contexto actual o con el indicado como argumento.
+func (r *hbsRenderer) renderPartial(sb *strings.Builder, node *hbsNode, stack []hbsFrame) error {
+	partial, ok := storage.GetPartial(node.text)
+	if !ok {
+		return fmt.Errorf("partial '%s' no encontrado", node.text)
+	}
+	if engine := partial.PartialEngine(); engine != models.TemplateEngineHandlebars {
+		return fmt.Errorf("el partial '%s' usa el motor '%s' y no puede incluirse desde una plantilla handlebars", node.text, engine)
+	}
+	if r.depth >= maxHandlebarsPartialDepth {
+		return fmt.Errorf("se superó la profundidad máxima de partials (%d)", maxHandlebarsPartialDepth)
+	}
+
+	nodes, err := parseHandlebars(partial.Template)
+	if err != nil {
+		return fmt.Errorf("partial '%s': %w", node.text, err)
+	}
+
+	if len(node.expr.params) > 0 {
+		ctx, err := r.evalArg(node.expr.params[0], stack)
+		if err != nil {
+			return err
+		}
+		stack = append(stack, hbsFrame{this: ctx})
+	}
+
+	r.depth++
+	defer func() { r.depth-- }()
+	return r.renderNodes(sb, nodes, stack)
+}
+
+// evalExpr evalúa una expresión. Si tiene parámetros, o su nombre es un helper sin puntos, se llama al helper;
+// en caso contrario se resuelve como ruta en el contexto.
+func (r *hbsRenderer) evalExpr(expr *hbsExpr, stack []hbsFrame) (interface{}, error) {
+	if expr.name.isLit || expr.name.sub != nil {
+		if len(expr.params) > 0 {
+			return nil, fmt.Errorf("no se puede llamar a un literal con argumentos")
+		}
+		return r.evalArg(expr.name, stack)
+	}
+
+	if fn, ok := r.lookupHelper(expr.name.path); ok {
+		args := make([]interface{}, len(expr.params))
+		for i, p := range expr.params {
+			v, err := r.evalArg(p, stack)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		return callHelper(expr.name.path, fn, args)
+	}
+
+	if len(expr.params) > 0 {
+		return nil, fmt.Errorf("helper desconocido '%s'", expr.name.path)
+	}
+	return resolveHandlebarsPath(expr.name.path, stack), nil
+}
+
+// lookupHelper busca un helper por nombre. Un nombre 'helper.método' invoca el helper sin argumentos y
+// devuelve el método exportado del objeto resultante, ej. '{{store.set "clave" valor}}' llama a Set.
+func (r *hbsRenderer) lookupHelper(name string) (interface{}, bool) {
+	if fn, ok := r.funcs[name]; ok {
+		return fn, true
+	}
+	head, method, found := strings.Cut(name, ".")
+	if !found || method == "" || strings.Contains(method, ".") {
+		return nil, false
+	}
+	fn, ok := r.funcs[head]
+	if !ok {
+		return nil, false
+	}
+	obj, err := callHelper(head, fn, nil)
+	if err != nil || obj == nil {
+		return nil, false
+	}
+	m := reflect.ValueOf(obj).MethodByName(strings.ToUpper(method[:1]) + method[1:])
+	if !m.IsValid() {
+		return nil, false
+	}
+	return m.Interface(), true
+}
+
+// evalArg evalúa un argumento: literal, subexpresión o ruta.
+func (r *hbsRenderer) evalArg(arg *hbsArg, stack []hbsFrame) (interface{}, error) {
+	switch {
+	case arg.isLit:
+		return arg.literal, nil
+	case arg.sub != nil:
+		return r.evalExpr(arg.sub, stack)
+	}
+	// Un helper sin argumentos también puede usarse como valor, ej. '{{default uuid request.query.id}}'
+	if fn, ok := r.funcs[arg.path]; ok && !strings.ContainsAny(arg.path, "./@") {
+		return callHelper(arg.path, fn, nil)
+	}
+	return resolveHandlebarsPath(arg.path, stack), nil
+}
+
+// resolveHandlebarsPath busca una ruta ('request.query.name', 'this', '../x', '@index') en la pila de contextos.
+func resolveHandlebarsPath(path string, stack []hbsFrame) interface{} {
+	level := len(stack) - 1
+	for strings.HasPrefix(path, "../") {
+		path = path[3:]
+		if level > 0 {
+			level--
+		}
+	}
+	frame := stack[level]
+
+	if strings.HasPrefix(path, "@") {
+		if frame.data == nil {
+			return nil
+		}
+		return frame.data[path[1:]]
+	}
+
+	if path == "this" || path == "." {
+		return frame.this
+	}
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "this."), "./")
+
+	current := frame.this
+	for _, segment := range strings.Split(path, ".") {
+		segment = strings.Trim(segment, "[]")
+		current = hbsLookup(current, segment)
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// hbsLookup obtiene un campo de un mapa o un índice de una lista. En mapas con claves de texto
+// se intenta también una coincidencia sin distinguir mayúsculas, como hacen los headers en WireMock.
+func hbsLookup(v interface{}, key string) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && (rv.Kind() == reflect.Interface || rv.Kind() == reflect.Ptr) {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if !reflect.TypeOf(key).ConvertibleTo(rv.Type().Key()) {
+			return nil
+		}
+		if item := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())); item.IsValid() {
+			return item.Interface()
+		}
+		for _, k := range rv.MapKeys() {
+			if strings.EqualFold(k.String(), key) {
+				return rv.MapIndex(k).Interface()
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		idx, err := strconv.Atoi(key)
+		if err == nil && idx >= 0 && idx < rv.Len() {
+			return rv.Index(idx).Interface()
+		}
+		if key == "length" {
+			return rv.Len()
+		}
+	case reflect.String:
+		if key == "length" {
+			return len([]rune(rv.String()))
+		}
+	}
+	return nil
+}
+
+// callHelper invoca una función de la biblioteca compartida convirtiendo los argumentos a los tipos esperados.
+// Si el helper entra en pánico con los argumentos recibidos, se devuelve un error.
+func callHelper(name string, fn interface{}, args []interface{}) (result interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			result, err = nil, fmt.Errorf("%s: %v", name, p)
+		}
+	}()
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	numFixed := ft.NumIn()
+	if ft.IsVariadic() {
+		numFixed--
+		if len(args) < numFixed {
+			return nil, fmt.Errorf("%s: se esperaban al menos %d argumentos y se recibieron %d", name, numFixed, len(args))
+		}
+	} else if len(args) != numFixed {
+		return nil, fmt.Errorf("%s: se esperaban %d argumentos y se recibieron %d", name, numFixed, len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var paramType reflect.Type
+		if ft.IsVariadic() && i >= numFixed {
+			paramType = ft.In(ft.NumIn() - 1).Elem()
+		} else {
+			paramType = ft.In(i)
+		}
+		v, err := convertHelperArg(arg, paramType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: argumento %d: %w", name, i+1, err)
+		}
+		in[i] = v
+	}
+
+	out := fv.Call(in)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0].Interface(), nil
+}
+
+// convertHelperArg adapta un valor al tipo del parámetro del helper.
+func convertHelperArg(arg interface{}, t reflect.Type) (reflect.Value, error) {
+	if arg == nil {
+		return reflect.Zero(t), nil
+	}
+	v := reflect.ValueOf(arg)
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(toString(arg)).Convert(t), nil
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		n, err := toInt(arg)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	case reflect.Float64:
+		f, err := toFloat(arg)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(f), nil
+	}
+	if v.Type().ConvertibleTo(t) {
+		return v.Convert(t), nil
+	}
+	return reflect.Value{}, fmt.Errorf("no se puede convertir %T a %s", arg, t)
+}
+
+// hbsTruthy aplica las reglas de verdad de Handlebars: false, nil, "", 0 y listas vacías son falsos.
+func hbsTruthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String, reflect.Slice, reflect.Array:
+		return rv.Len() > 0
+	case reflect.Map:
+		return true
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return rv.Int() != 0
+	case reflect.Float64, reflect.Float32:
+		return rv.Float() != 0
+	case reflect.Ptr, reflect.Interface:
+		return !rv.IsNil()
+	}
+	return true
+}
+
+// hbsToString convierte un valor a texto; los mapas y listas se imprimen como JSON.
+func hbsToString(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsValid() && (rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) {
+		if _, isBytes := v.([]byte); !isBytes {
+			b, err := json.Marshal(v)
+			if err == nil {
+				return string(b)
+			}
+		}
+	}
+	return toString(v)
+}
+
+// hbsEscape escapa los mismos caracteres que Handlebars: & < > " ' ` =
+func hbsEscape(s string) string {
+	s = html.EscapeString(s) // & < > " '
+	return strings.NewReplacer("`", "&#x60;", "=", "&#x3D;").Replace(s)
+}
+
+// toHandlebarsContext adapta el contexto versionado a la convención camelCase de Handlebars/WireMock:
+// '.Request.Query.name' se expone como 'request.query.name' y '.Mock.Id' como 'mock.id'.
+// Solo se renombran las claves del propio contexto, nunca las de los datos de la solicitud.
+func toHandlebarsContext(ctx fiber.Map) map[string]interface{} {
+	out := make(map[string]interface{}, len(ctx))
+	for key, value := range ctx {
+		if nested, ok := value.(fiber.Map); ok {
+			inner := make(map[string]interface{}, len(nested))
+			for k, v := range nested {
+				inner[lowerFirst(k)] = v
+			}
+			value = inner
+		}
+		out[lowerFirst(key)] = value
+	}
+	return out
+}
+
+// lowerFirst pone en minúscula la primera letra ('PathParams' -> 'pathParams', 'Id' -> 'id').
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
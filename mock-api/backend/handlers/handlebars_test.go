@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"backend/models"
+	"backend/storage"
+)
+
+// hbsTestData es el contexto de las pruebas del motor Handlebars, con la forma del contexto de una solicitud.
+var hbsTestData = map[string]interface{}{
+	"request": map[string]interface{}{
+		"query":   map[string]interface{}{"name": "<Ana>"},
+		"headers": map[string]interface{}{"x-tenant": "acme"},
+		"body": map[string]interface{}{
+			"items": []interface{}{"a", "b", "c"},
+			"user":  map[string]interface{}{"id": 7.0, "name": "Luis"},
+			"stock": map[string]interface{}{"b": 2.0, "a": 1.0},
+		},
+	},
+	"codes": map[int]string{2: "dos", 10: "diez"},
+	"empty": []interface{}{},
+}
+
+func TestRenderHandlebars(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{`{{request.query.name}}`, "&lt;Ana&gt;"},
+		{`{{{request.query.name}}}`, "<Ana>"},
+		{`{{request.headers.X-Tenant}}`, "acme"},
+		{`[{{request.query.nope}}]`, "[]"},
+		{`{{request.body.items.length}}`, "3"},
+		{`{{#each request.body.items}}{{@index}}={{this}}{{#if @last}}.{{else}},{{/if}}{{/each}}`, "0=a,1=b,2=c."},
+		{`{{#each request.body.stock}}{{@key}}:{{this}};{{/each}}`, "a:1;b:2;"},
+		{`{{#each codes}}{{@key}}={{this}} {{/each}}`, "10=diez 2=dos "},
+		{`{{#each empty}}x{{else}}vacío{{/each}}`, "vacío"},
+		{`{{#unless request.query.nope}}sin nombre{{/unless}}`, "sin nombre"},
+		{`{{#with request.body.user}}{{name}}@{{../request.headers.x-tenant}}{{/with}}`, "Luis@acme"},
+		{`{{upper (default "anónimo" request.query.nope)}}`, "ANÓNIMO"},
+		{`{{add request.body.user.id 3}}`, "10"},
+		{"a {{!-- nota --}} {{~ request.body.user.name ~}} b", "a Luisb"},
+	}
+	for _, tt := range tests {
+		got, err := renderHandlebars(tt.tmpl, templateFuncMap(), hbsTestData)
+		if err != nil {
+			t.Errorf("%s: error inesperado: %v", tt.tmpl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %q, se esperaba %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestRenderHandlebarsErrors(t *testing.T) {
+	// Plantilla y parte del mensaje de error esperado
+	tests := map[string]string{
+		`{{#each request.query.name}}x{{/each}}`: "'#each' requiere una lista o un objeto",
+		`{{div 1 0}}`:                            "división entre cero",
+		`{{nope 1}}`:                             "helper desconocido 'nope'",
+		`{{upper}}`:                              "se esperaban 1 argumentos",
+		`{{#if request}}x`:                       "if",
+		`{{> nope}}`:                             "partial 'nope' no encontrado",
+		`{{boom "x"}}`:                           "boom: explota",
+	}
+	funcs := templateFuncMap()
+	funcs["boom"] = func(string) string { panic("explota") }
+	for tmpl, want := range tests {
+		got, err := renderHandlebars(tmpl, funcs, hbsTestData)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: se esperaba un error con %q, se obtuvo %v (salida %q)", tmpl, want, err, got)
+		}
+	}
+}
+
+func TestRenderHandlebarsPartials(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, partial := range []models.TemplatePartial{
+		{Name: "hbsUser", Template: `<{{name}}>`, Engine: models.TemplateEngineHandlebars},
+		{Name: "goUser", Template: `{{.name}}`},
+	} {
+		if err := storage.AddPartial(partial); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { storage.DeletePartial(partial.Name) })
+	}
+	data := map[string]interface{}{"user": map[string]interface{}{"name": "Ana"}}
+
+	got, err := renderHandlebars(`{{> hbsUser user}}`, templateFuncMap(), data)
+	if err != nil || got != "<Ana>" {
+		t.Errorf("partial con contexto = %q (%v), se esperaba %q", got, err, "<Ana>")
+	}
+
+	// Un partial de plantillas Go no se puede incluir desde Handlebars
+	if _, err := renderHandlebars(`{{> goUser user}}`, templateFuncMap(), data); err == nil || !strings.Contains(err.Error(), "usa el motor 'go'") {
+		t.Errorf("partial de otro motor: se obtuvo %v", err)
+	}
+}
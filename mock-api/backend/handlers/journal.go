@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// journalBodyLimit es el tamaño máximo del body que se guarda de cada solicitud en el journal.
+const journalBodyLimit = 64 << 10
+
+// Cantidad de solicitudes que devuelve GET /__admin/requests si no se indica 'limit'.
+const defaultJournalLimit = 100
+
+// recordJournalEntry completa la entrada del journal con los datos de la solicitud, el código de estado y la
+// latencia, y la guarda en el journal del workspace. Si el handler devolvió un error, el código de estado es
+// el que le asignará el manejador de errores global.
+func recordJournalEntry(c *fiber.Ctx, req requestData, entry models.JournalEntry, start time.Time, err error) {
+	entry.Id = uuid.New().String()
+	entry.Timestamp = start.UTC()
+	entry.Method = req.Method
+	entry.Path = req.Path
+	entry.Query = req.Query
+	entry.Headers = req.Headers
+	entry.Body = req.RawBody
+	if len(entry.Body) > journalBodyLimit {
+		entry.Body = truncateUTF8(entry.Body, journalBodyLimit)
+		entry.BodyTruncated = true
+	}
+	entry.Status = c.Response().StatusCode()
+	if err != nil {
+		entry.Status = fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			entry.Status = fiberErr.Code
+		}
+	}
+	entry.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	storage.AddJournalEntry(workspaceOf(c), entry)
+}
+
+// truncateUTF8 recorta s a lo sumo a limit bytes sin partir un carácter UTF-8: si el corte cae dentro de
+// un carácter se retrocede hasta su inicio. En un texto que no es UTF-8 se retroceden como máximo 3 bytes.
+func truncateUTF8(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && cut > limit-utf8.UTFMax+1 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// GetJournal maneja la solicitud GET /__admin/requests.
+// Devuelve las solicitudes recibidas por el workspace, de la más reciente a la más antigua. Filtros opcionales:
+// path (exacto), pathPrefix, method, mockId, matched (true/false), from y to (RFC 3339) y limit (por defecto 100).
+func GetJournal(c *fiber.Ctx) error {
+	filter, err := parseJournalFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Filtro de solicitudes inválido", "details": err.Error()})
+	}
+
+	workspace := workspaceOf(c)
+	entries, total := storage.QueryJournal(workspace, filter)
+	c.Set("X-Total-Count", strconv.Itoa(total))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"workspace": workspace,
+		"capacity":  storage.JournalCapacity(),
+		"total":     total,
+		"requests":  entries,
+	})
+}
+
+// parseJournalFilter lee los filtros de GET /__admin/requests desde el query string.
+func parseJournalFilter(c *fiber.Ctx) (models.JournalFilter, error) {
+	filter := models.JournalFilter{
+		Path:       strings.Clone(c.Query("path")),
+		PathPrefix: strings.Clone(c.Query("pathPrefix")),
+		Method:     strings.Clone(c.Query("method")),
+		MockId:     strings.Clone(c.Query("mockId")),
+		Limit:      defaultJournalLimit,
+	}
+
+	if value := c.Query("matched"); value != "" {
+		matched, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("'matched' debe ser true o false")
+		}
+		filter.Matched = &matched
+	}
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("'%s' debe ser una fecha RFC 3339, ej. 2024-01-31T10:00:00Z", bound.name)
+		}
+		*bound.target = t
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return filter, fmt.Errorf("'to' no puede ser anterior a 'from'")
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return filter, fmt.Errorf("'limit' debe ser un entero positivo")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
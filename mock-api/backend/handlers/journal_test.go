@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		s     string
+		limit int
+		want  string
+	}{
+		{"hola", 10, "hola"},
+		{"hola", 4, "hola"},
+		{"hola", 2, "ho"},
+		{"añejo", 2, "a"},                           // 'ñ' ocupa los bytes 1 y 2
+		{"añejo", 3, "añ"},                          // el corte cae justo después de 'ñ'
+		{"a😀b", 4, "a"},                             // '😀' ocupa los bytes 1 a 4
+		{"a😀b", 5, "a😀"},                            // el corte cae justo después de '😀'
+		{"😀", 3, ""},                                // no entra ningún carácter completo
+		{"ab\x80\x80\x80\x80", 5, "ab"},             // retrocede hasta el inicio del carácter inválido
+		{"\x80\x80\x80\x80\x80\x80", 5, "\x80\x80"}, // sin un inicio de carácter retrocede como máximo 3 bytes
+	}
+	for _, tt := range tests {
+		if got := truncateUTF8(tt.s, tt.limit); got != tt.want {
+			t.Errorf("truncateUTF8(%q, %d) = %q, se esperaba %q", tt.s, tt.limit, got, tt.want)
+		}
+	}
+
+	// Un body grande cortado en el límite del journal sigue siendo UTF-8 válido
+	body := "x" + strings.Repeat("ñ", journalBodyLimit)
+	if got := truncateUTF8(body, journalBodyLimit); !utf8.ValidString(got) || len(got) != journalBodyLimit-1 {
+		t.Errorf("body truncado de %d bytes, válido: %v", len(got), utf8.ValidString(got))
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"backend/models"
+)
+
+// applyMergePatch aplica un JSON Merge Patch (RFC 7386) sobre un documento:
+// las claves con valor null se eliminan, los objetos se combinan recursivamente
+// y cualquier otro valor reemplaza al original.
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = applyMergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// applyJSONPatch aplica una lista de operaciones JSON Patch (RFC 6902) sobre un documento y devuelve
+// el documento resultante. Las operaciones se aplican en orden y la primera que falla detiene el proceso.
+func applyJSONPatch(doc interface{}, ops []models.JSONPatchOperation) (interface{}, error) {
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case "add":
+			doc, err = jsonPointerSet(doc, op.Path, deepCopyJSON(op.Value), true)
+		case "remove":
+			doc, _, err = jsonPointerRemove(doc, op.Path)
+		case "replace":
+			if _, err = jsonPointerGet(doc, op.Path); err == nil {
+				doc, err = jsonPointerSet(doc, op.Path, deepCopyJSON(op.Value), false)
+			}
+		case "move":
+			var value interface{}
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				err = fmt.Errorf("no se puede mover '%s' dentro de sí mismo", op.From)
+				break
+			}
+			if doc, value, err = jsonPointerRemove(doc, op.From); err == nil {
+				doc, err = jsonPointerSet(doc, op.Path, value, true)
+			}
+		case "copy":
+			var value interface{}
+			if value, err = jsonPointerGet(doc, op.From); err == nil {
+				doc, err = jsonPointerSet(doc, op.Path, deepCopyJSON(value), true)
+			}
+		case "test":
+			var value interface{}
+			if value, err = jsonPointerGet(doc, op.Path); err == nil && !reflect.DeepEqual(normalizeJSON(value), normalizeJSON(op.Value)) {
+				err = fmt.Errorf("el valor en '%s' no coincide", op.Path)
+			}
+		default:
+			err = fmt.Errorf("operación '%s' no soportada", op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("operación %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// validateJSONPatch valida la estructura de las operaciones JSON Patch sin aplicarlas.
+func validateJSONPatch(ops []models.JSONPatchOperation) error {
+	for i, op := range ops {
+		switch op.Op {
+		case "add", "remove", "replace", "test":
+		case "move", "copy":
+			if _, err := parseJSONPointer(op.From); err != nil {
+				return fmt.Errorf("operación %d: 'from' inválido: %w", i, err)
+			}
+		default:
+			return fmt.Errorf("operación %d: 'op' debe ser add, remove, replace, move, copy o test", i)
+		}
+		if _, err := parseJSONPointer(op.Path); err != nil {
+			return fmt.Errorf("operación %d: 'path' inválido: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// parseJSONPointer divide un JSON Pointer (RFC 6901) en sus segmentos, decodificando '~1' y '~0'.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("'%s' debe iniciar con '/'", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+// jsonArrayIndex interpreta un segmento como índice de una lista de tamaño n.
+// Con allowEnd se acepta '-' y el índice n (posición después del último elemento).
+func jsonArrayIndex(segment string, n int, allowEnd bool) (int, error) {
+	if segment == "-" && allowEnd {
+		return n, nil
+	}
+	idx, err := strconv.Atoi(segment)
+	if err != nil || idx < 0 || (segment != "0" && strings.HasPrefix(segment, "0")) {
+		return 0, fmt.Errorf("índice '%s' inválido", segment)
+	}
+	if idx > n || (idx == n && !allowEnd) {
+		return 0, fmt.Errorf("índice %d fuera de rango", idx)
+	}
+	return idx, nil
+}
+
+// jsonPointerGet obtiene el valor al que apunta un JSON Pointer.
+func jsonPointerGet(doc interface{}, pointer string) (interface{}, error) {
+	segments, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("la ruta '%s' no existe", pointer)
+			}
+			current = value
+		case []interface{}:
+			idx, err := jsonArrayIndex(segment, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[idx]
+		default:
+			return nil, fmt.Errorf("la ruta '%s' no existe", pointer)
+		}
+	}
+	return current, nil
+}
+
+// jsonPointerSet asigna un valor en la ubicación de un JSON Pointer y devuelve el documento resultante.
+// Con insert (operación add) los elementos de listas se insertan; sin insert se reemplazan.
+func jsonPointerSet(doc interface{}, pointer string, value interface{}, insert bool) (interface{}, error) {
+	segments, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return value, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := jsonPointerGet(doc, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+	last := segments[len(segments)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		idx, err := jsonArrayIndex(last, len(node), insert)
+		if err != nil {
+			return nil, err
+		}
+		if insert {
+			node = append(node, nil)
+			copy(node[idx+1:], node[idx:])
+		}
+		node[idx] = value
+		// Las listas pueden cambiar de tamaño, por lo que se reasignan en su contenedor
+		return jsonPointerSet(doc, parentPointer, node, false)
+	default:
+		return nil, fmt.Errorf("la ruta '%s' no existe", parentPointer)
+	}
+}
+
+// jsonPointerRemove elimina el valor al que apunta un JSON Pointer y devuelve el documento resultante
+// junto con el valor eliminado.
+func jsonPointerRemove(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	segments, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(segments) == 0 {
+		return nil, doc, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := jsonPointerGet(doc, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := segments[len(segments)-1]
+
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("la ruta '%s' no existe", pointer)
+		}
+		delete(node, last)
+		return doc, value, nil
+	case []interface{}:
+		idx, err := jsonArrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[idx]
+		node = append(node[:idx:idx], node[idx+1:]...)
+		doc, err = jsonPointerSet(doc, parentPointer, node, false)
+		return doc, value, err
+	default:
+		return nil, nil, fmt.Errorf("la ruta '%s' no existe", pointer)
+	}
+}
+
+// deepCopyJSON copia un valor JSON para que las operaciones no compartan mapas ni listas.
+func deepCopyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = deepCopyJSON(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = deepCopyJSON(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// normalizeJSON convierte un valor a su forma decodificada de JSON para compararlo (ej. int -> float64).
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return value
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend/models"
+)
+
+// decodeJSON parsea un documento JSON de prueba.
+func decodeJSON(t *testing.T, src string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(src), &v); err != nil {
+		t.Fatalf("JSON de prueba inválido %q: %v", src, err)
+	}
+	return v
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	// Ejemplos del apéndice A de RFC 7386: documento, patch y resultado
+	examples := [][3]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, ex := range examples {
+		got := applyMergePatch(decodeJSON(t, ex[0]), decodeJSON(t, ex[1]))
+		if want := decodeJSON(t, ex[2]); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s + %s = %v, se esperaba %v", ex[0], ex[1], got, want)
+		}
+	}
+}
+
+// decodePatch parsea una lista de operaciones JSON Patch de prueba y verifica que sea válida.
+func decodePatch(t *testing.T, src string) []models.JSONPatchOperation {
+	t.Helper()
+	var ops []models.JSONPatchOperation
+	if err := json.Unmarshal([]byte(src), &ops); err != nil {
+		t.Fatalf("patch de prueba inválido %q: %v", src, err)
+	}
+	if err := validateJSONPatch(ops); err != nil {
+		t.Fatalf("patch de prueba inválido %q: %v", src, err)
+	}
+	return ops
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	// Ejemplos del apéndice A de RFC 6902, más algunos casos propios
+	examples := []struct {
+		name, doc, patch, want string
+	}{
+		{"A.1", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"A.2", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"A.3", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"A.4", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"A.5", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"A.6", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"A.7", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"A.8", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"A.10", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"A.14", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"A.16", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"documento completo", `{"a":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{"copy independiente", `{"a":{"x":1}}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"replace","path":"/b/x","value":2}]`, `{"a":{"x":1},"b":{"x":2}}`},
+	}
+	for _, ex := range examples {
+		t.Run(ex.name, func(t *testing.T) {
+			got, err := applyJSONPatch(decodeJSON(t, ex.doc), decodePatch(t, ex.patch))
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if want := decodeJSON(t, ex.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("se obtuvo %v, se esperaba %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	examples := []struct {
+		name, doc, patch, wantErr string
+	}{
+		{"A.9 test falla", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "no coincide"},
+		{"A.12 padre inexistente", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "operación 0 (add /baz/bat)"},
+		{"replace inexistente", `{"a":1}`, `[{"op":"replace","path":"/b","value":2}]`, "operación 0 (replace /b)"},
+		{"move dentro de sí mismo", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, "dentro de sí mismo"},
+		{"índice con cero inicial", `{"l":[1,2]}`, `[{"op":"remove","path":"/l/01"}]`, "índice '01' inválido"},
+		{"índice fuera de rango", `{"l":[1]}`, `[{"op":"add","path":"/l/5","value":2}]`, "fuera de rango"},
+		{"se detiene en la primera falla", `{"a":1}`, `[{"op":"add","path":"/b","value":2},{"op":"remove","path":"/c"}]`, "operación 1 (remove /c)"},
+	}
+	for _, ex := range examples {
+		t.Run(ex.name, func(t *testing.T) {
+			_, err := applyJSONPatch(decodeJSON(t, ex.doc), decodePatch(t, ex.patch))
+			if err == nil || !strings.Contains(err.Error(), ex.wantErr) {
+				t.Errorf("se esperaba un error con %q, se obtuvo %v", ex.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateJSONPatch(t *testing.T) {
+	invalid := map[string][]models.JSONPatchOperation{
+		"'op' debe ser":     {{Op: "merge", Path: "/a"}},
+		"'path' inválido":   {{Op: "remove", Path: "a"}},
+		"'from' inválido":   {{Op: "move", From: "a", Path: "/b"}},
+		"operación 1: 'op'": {{Op: "add", Path: "/a", Value: 1}, {Op: "Add", Path: "/b"}},
+	}
+	for want, ops := range invalid {
+		if err := validateJSONPatch(ops); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%+v: se esperaba un error con %q, se obtuvo %v", ops, want, err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Valores por defecto y límites de los callbacks salientes
+const (
+	defaultCallbackMethod     = "POST"
+	defaultCallbackHmacHeader = "X-Signature"
+	defaultCallbackRetryDelay = 1000 * time.Millisecond
+	maxCallbackRetries        = 10
+	maxCallbackDelay          = 5 * time.Minute
+	callbackTimeout           = 10 * time.Second
+)
+
+// callbackClient es el cliente HTTP compartido por todos los callbacks.
+var callbackClient = &http.Client{Timeout: callbackTimeout}
+
+// preparedCallback es un callback con su URL, headers y body ya evaluados.
+type preparedCallback struct {
+	config  models.MockCallback
+	url     string
+	headers map[string]string
+	body    []byte
+}
+
+// renderCallbackBody evalúa el body de un callback. Un string se evalúa como una sola plantilla; en un
+// objeto o lista cada string se evalúa por separado y el resultado se serializa a JSON, de modo que un
+// valor con comillas o saltos de línea no rompe el JSON enviado.
+func renderCallbackBody(body interface{}, render func(name, src string) (string, error)) ([]byte, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		rendered, err := render("callbackBody", b)
+		return []byte(rendered), err
+	}
+	rendered, err := renderStringLeaves(body, render)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rendered)
+}
+
+// renderStringLeaves devuelve una copia del valor con cada string evaluado como plantilla.
+func renderStringLeaves(value interface{}, render func(name, src string) (string, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return render("callbackBody", v)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := renderStringLeaves(item, render)
+			if err != nil {
+				return nil, fmt.Errorf("'%s': %w", key, err)
+			}
+			rendered[key] = r
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := renderStringLeaves(item, render)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			rendered[i] = r
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+// normalizeCallbacks aplica los valores por defecto de los callbacks de un mock y valida
+// sus campos y la sintaxis de sus plantillas con el motor indicado.
+func normalizeCallbacks(callbacks []models.MockCallback, engine string) error {
+	for i := range callbacks {
+		cb := &callbacks[i]
+		cb.URL = strings.TrimSpace(cb.URL)
+		cb.Method = strings.ToUpper(strings.TrimSpace(cb.Method))
+		if cb.Method == "" {
+			cb.Method = defaultCallbackMethod
+		}
+		if cb.HmacSecret != "" && strings.TrimSpace(cb.HmacHeader) == "" {
+			cb.HmacHeader = defaultCallbackHmacHeader
+		}
+
+		if cb.URL == "" {
+			return fmt.Errorf("callback %d: el campo 'url' es requerido", i)
+		}
+		// Una URL con plantillas solo puede validarse al evaluarla (ver prepareCallback)
+		if !strings.Contains(cb.URL, "{{") {
+			if err := validateUpstreamURL(cb.URL); err != nil {
+				return fmt.Errorf("callback %d: 'url' inválida: %w", i, err)
+			}
+		}
+		switch cb.Method {
+		case "GET", "POST", "PUT", "PATCH", "DELETE":
+		default:
+			return fmt.Errorf("callback %d: método '%s' inválido. Los métodos permitidos son: GET, POST, PUT, PATCH, DELETE", i, cb.Method)
+		}
+		if cb.DelayMs < 0 || time.Duration(cb.DelayMs)*time.Millisecond > maxCallbackDelay {
+			return fmt.Errorf("callback %d: 'delayMs' debe estar entre 0 y %d", i, maxCallbackDelay.Milliseconds())
+		}
+		if cb.Retries < 0 || cb.Retries > maxCallbackRetries {
+			return fmt.Errorf("callback %d: 'retries' debe estar entre 0 y %d", i, maxCallbackRetries)
+		}
+		if cb.RetryDelayMs < 0 || time.Duration(cb.RetryDelayMs)*time.Millisecond > maxCallbackDelay {
+			return fmt.Errorf("callback %d: 'retryDelayMs' debe estar entre 0 y %d", i, maxCallbackDelay.Milliseconds())
+		}
+
+		// Validar la sintaxis de las plantillas antes de guardarlas
+		for _, src := range append([]string{cb.URL}, getValues(cb.Headers)...) {
+			if err := checkTemplateSyntax(src, engine); err != nil {
+				return fmt.Errorf("callback %d: plantilla inválida: %w", i, err)
+			}
+		}
+		check := func(_, src string) (string, error) { return src, checkTemplateSyntax(src, engine) }
+		if _, err := renderCallbackBody(cb.Body, check); err != nil {
+			return fmt.Errorf("callback %d: 'body' inválido: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkTemplateSyntax valida la sintaxis de una plantilla con el motor indicado, sin ejecutarla.
+func checkTemplateSyntax(src, engine string) error {
+	if engine == models.TemplateEngineHandlebars {
+		_, err := parseHandlebars(src)
+		return err
+	}
+	set, err := newTemplateSet(templateFuncMap())
+	if err != nil {
+		return err
+	}
+	_, err = set.New("check").Parse(capitalizeStoreCalls(src))
+	return err
+}
+
+// triggerCallbacks evalúa los callbacks del mock con el contexto de la solicitud y la respuesta ya
+// generada ('.Response.Status' y '.Response.Body') y los envía en segundo plano.
+func triggerCallbacks(c *fiber.Ctx, config models.MockConfig, req requestData, pathParams map[string]string) {
+	// La respuesta se copia porque el buffer de Fiber se reutiliza al terminar el handler
+	var responseBody interface{} = string(c.Response().Body())
+	var parsed interface{}
+	if err := json.Unmarshal(c.Response().Body(), &parsed); err == nil {
+		responseBody = parsed
+	}
+
+	templateData := buildTemplateContext(req, pathParams, config)
+	templateData["Response"] = fiber.Map{
+		"Status": c.Response().StatusCode(),
+		"Body":   responseBody,
+	}
+	funcs := templateFuncMap()
+	render := func(name, src string) (string, error) {
+		return renderTemplate(name, src, funcs, templateData)
+	}
+	if config.TemplateEngine == models.TemplateEngineHandlebars {
+		hbsData := toHandlebarsContext(templateData)
+		render = func(_, src string) (string, error) {
+			return renderHandlebars(src, funcs, hbsData)
+		}
+	}
+
+	for i, cb := range config.Callbacks {
+		prepared, err := prepareCallback(cb, render)
+		if err != nil {
+			log.Printf("Error al evaluar el callback %d del mock %s: %v", i, config.Id, err)
+			continue
+		}
+		go sendCallback(config.Id, prepared)
+	}
+}
+
+// prepareCallback evalúa la URL, los headers y el body de un callback y calcula su firma HMAC.
+func prepareCallback(cb models.MockCallback, render func(name, src string) (string, error)) (preparedCallback, error) {
+	prepared := preparedCallback{config: cb, headers: make(map[string]string, len(cb.Headers)+2)}
+
+	url, err := render("callbackUrl", cb.URL)
+	if err != nil {
+		return prepared, fmt.Errorf("url: %w", err)
+	}
+	prepared.url = strings.TrimSpace(url)
+	if err := validateUpstreamURL(prepared.url); err != nil {
+		return prepared, fmt.Errorf("url: %w", err)
+	}
+
+	for name, value := range cb.Headers {
+		rendered, err := render("callbackHeader", value)
+		if err != nil {
+			return prepared, fmt.Errorf("header '%s': %w", name, err)
+		}
+		prepared.headers[name] = rendered
+	}
+
+	body, err := renderCallbackBody(cb.Body, render)
+	if err != nil {
+		return prepared, fmt.Errorf("body: %w", err)
+	}
+	prepared.body = body
+
+	if !hasHeader(prepared.headers, "Content-Type") && len(prepared.body) > 0 {
+		prepared.headers["Content-Type"] = "application/json"
+	}
+	// Firma HMAC-SHA256 del body, con el formato 'sha256=<hex>'
+	if cb.HmacSecret != "" {
+		mac := hmac.New(sha256.New, []byte(cb.HmacSecret))
+		mac.Write(prepared.body)
+		prepared.headers[cb.HmacHeader] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return prepared, nil
+}
+
+// hasHeader indica si los headers incluyen el nombre indicado, sin distinguir mayúsculas de minúsculas.
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendCallback envía un callback después de su retraso, reintentando mientras la respuesta
+// no sea 2xx o falle la conexión.
+func sendCallback(mockID string, cb preparedCallback) {
+	if cb.config.DelayMs > 0 {
+		time.Sleep(time.Duration(cb.config.DelayMs) * time.Millisecond)
+	}
+	retryDelay := defaultCallbackRetryDelay
+	if cb.config.RetryDelayMs > 0 {
+		retryDelay = time.Duration(cb.config.RetryDelayMs) * time.Millisecond
+	}
+
+	attempts := cb.config.Retries + 1
+	for attempt := 1; attempt <= attempts; attempt++ {
+		status, err := doCallbackRequest(cb)
+		if err == nil && status >= 200 && status < 300 {
+			log.Printf("Callback del mock %s enviado: %s %s -> %d (intento %d/%d)", mockID, cb.config.Method, cb.url, status, attempt, attempts)
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("respuesta con código %d", status)
+		}
+		log.Printf("Error en el callback del mock %s: %s %s: %v (intento %d/%d)", mockID, cb.config.Method, cb.url, err, attempt, attempts)
+		if attempt < attempts {
+			time.Sleep(retryDelay)
+		}
+	}
+}
+
+// doCallbackRequest realiza una solicitud HTTP de callback y devuelve el código de estado.
+func doCallbackRequest(cb preparedCallback) (int, error) {
+	request, err := http.NewRequest(cb.config.Method, cb.url, bytes.NewReader(cb.body))
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range cb.headers {
+		request.Header.Set(name, value)
+	}
+	response, err := callbackClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	return response.StatusCode, nil
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestNormalizeCallbacksURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{"http://localhost:8081/webhooks", ""},
+		{" https://hooks.example.com/pagos ", ""},
+		{"{{.Request.Body.callbackUrl}}", ""},
+		{"", "es requerido"},
+		{"/webhooks/pagos", "URL absoluta"},
+		{"localhost:8081/webhooks", "URL absoluta"},
+		{"ftp://files.example.com/x", "URL absoluta"},
+		{"http://", "URL absoluta"},
+		{"http://%zz", "inválida"},
+	}
+	for _, tt := range tests {
+		err := normalizeCallbacks([]models.MockCallback{{URL: tt.url}}, models.TemplateEngineGo)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("url %q: error inesperado: %v", tt.url, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("url %q: se obtuvo %v, se esperaba un error con %q", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPrepareCallback(t *testing.T) {
+	literal := func(_, src string) (string, error) { return src, nil }
+
+	// Un Content-Type propio se respeta sin importar cómo se escriba su nombre
+	cb := models.MockCallback{URL: "http://localhost/hook", Headers: map[string]string{"content-type": "text/plain"}, Body: "hola"}
+	prepared, err := prepareCallback(cb, literal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prepared.headers) != 1 || prepared.headers["content-type"] != "text/plain" {
+		t.Errorf("headers = %v, se esperaba solo content-type: text/plain", prepared.headers)
+	}
+
+	cb.Headers = nil
+	if prepared, err = prepareCallback(cb, literal); err != nil || prepared.headers["Content-Type"] != "application/json" {
+		t.Errorf("sin Content-Type: headers = %v (%v), se esperaba application/json", prepared.headers, err)
+	}
+
+	// La URL evaluada también debe ser absoluta
+	render := func(_, src string) (string, error) { return strings.ReplaceAll(src, "{{host}}", ""), nil }
+	if _, err := prepareCallback(models.MockCallback{URL: "{{host}}/hook"}, render); err == nil {
+		t.Error("URL evaluada relativa: se esperaba un error")
+	}
+}
+
+func TestCallbacksSkippedOnFailedResponse(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	storage.InitMockStorage()
+
+	hits := make(chan string, 4)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- r.URL.Path
+	}))
+	defer hook.Close()
+
+	// Los dos mocks tienen un callback; solo el que responde correctamente debe enviarlo
+	for id, body := range map[string]string{"ok": `{"ok": true}`, "falla": `{{div 1 0}}`} {
+		config := models.MockConfig{
+			Id: id, Path: "/" + id, Method: "GET", ResponseStatusCode: 200, ResponseBody: body, ContentType: "application/json",
+			IsTemplate: true, TemplateVersion: models.CurrentTemplateVersion,
+			Callbacks: []models.MockCallback{{URL: hook.URL + "/" + id, Method: "POST"}},
+		}
+		if err := storage.AddMockConfig(storage.DefaultWorkspace, config, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := fiber.New()
+	app.All("/*", ExecuteMock)
+	for path, wantStatus := range map[string]int{"/falla": 500, "/ok": 200} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != wantStatus {
+			t.Errorf("GET %s: código %d, se esperaba %d", path, resp.StatusCode, wantStatus)
+		}
+	}
+
+	select {
+	case path := <-hits:
+		if path != "/ok" {
+			t.Errorf("se envió el callback de %s", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no se envió el callback del mock que respondió correctamente")
+	}
+	select {
+	case path := <-hits:
+		t.Errorf("se envió el callback de %s", path)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
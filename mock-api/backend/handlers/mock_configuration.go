@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"backend/models"
@@ -21,32 +26,129 @@ func ConfigureMock(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear la configuración del mock", "details": err.Error()})
 	}
 
-	// Normalización de campos
-	config.Path = strings.TrimSpace(config.Path)
-	config.Method = strings.ToUpper(strings.TrimSpace(config.Method))
-	config.ContentType = strings.TrimSpace(config.ContentType)
-
 	// Generar un ID único para la configuración si no se proporciona
 	if config.Id == "" {
 		config.Id = uuid.New().String()
 	}
 
+	if errBody := validateMockConfig(&config); errBody != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errBody)
+	}
+
+	// Agregar la configuración del mock al almacenamiento. Un ID existente no se sobrescribe: para eso está PUT.
+	saved, err := storage.CreateMockConfig(workspaceOf(c), config, changeAuthor(c))
+	if err != nil {
+		if errors.Is(err, storage.ErrMockExists) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Ya existe un mock con el mismo ID. Use PUT /configure-mock/:id para reemplazarlo.", "id": config.Id})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración del mock en el almacenamiento persistente.", "details": err.Error()})
+	}
+
+	c.Set(fiber.HeaderETag, mockETag(saved.Revision))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Configuración de mock guardada exitosamente", "id": saved.Id, "revision": saved.Revision})
+}
+
+// validateMockConfig normaliza y valida una configuración de mock. Es compartida por la creación (POST),
+// el reemplazo (PUT) y la actualización parcial (PATCH). Devuelve el cuerpo del error 400, o nil si es válida.
+func validateMockConfig(config *models.MockConfig) fiber.Map {
+	// Normalización de campos
+	config.Path = strings.TrimSpace(config.Path)
+	config.Method = strings.ToUpper(strings.TrimSpace(config.Method))
+	config.ContentType = strings.TrimSpace(config.ContentType)
+
 	// VALIDACIONES
 	// Validaciones de campos requeridos
 	if config.Path == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'path' es requerido y no puede estar vacío."})
+		return fiber.Map{"error": "El campo 'path' es requerido y no puede estar vacío."}
 	}
 	if config.Method == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'method' es requerido y no puede estar vacío."})
+		return fiber.Map{"error": "El campo 'method' es requerido y no puede estar vacío."}
 	}
-	if config.ResponseStatusCode == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'responseStatusCode' es requerido y no puede ser 0."})
+	config.ResponseMode = strings.ToLower(strings.TrimSpace(config.ResponseMode))
+	config.ResponseStatusCodeTemplate = strings.TrimSpace(config.ResponseStatusCodeTemplate)
+	config.Tags = normalizeTags(config.Tags)
+
+	// Validación del modo de respuesta
+	if config.ResponseMode != models.ResponseModeProxy && (config.ProxyTo != "" || config.ProxyTransform != nil) {
+		return fiber.Map{"error": "Los campos 'proxyTo' y 'proxyTransform' requieren que 'responseMode' sea 'proxy'."}
+	}
+	switch config.ResponseMode {
+	case "", models.ResponseModeStatic:
+		if config.Script != "" {
+			return fiber.Map{"error": "El campo 'script' requiere que 'responseMode' sea 'script'."}
+		}
+	case models.ResponseModeScript:
+		if strings.TrimSpace(config.Script) == "" {
+			return fiber.Map{"error": "El campo 'script' es requerido cuando 'responseMode' es 'script'."}
+		}
+		if err := compileScript(config.Script); err != nil {
+			return fiber.Map{"error": "El campo 'script' contiene JavaScript inválido.", "details": err.Error()}
+		}
+		// El script decide el código de estado y el body; por defecto 200 y JSON
+		if config.ResponseStatusCode == 0 {
+			config.ResponseStatusCode = fiber.StatusOK
+		}
+		if config.ContentType == "" {
+			config.ContentType = "application/json"
+		}
+	case models.ResponseModeProxy:
+		config.ProxyTo = strings.TrimSpace(config.ProxyTo)
+		if err := validateUpstreamURL(config.ProxyTo); err != nil {
+			return fiber.Map{"error": "El campo 'proxyTo' es requerido cuando 'responseMode' es 'proxy' y debe ser una URL http o https.", "details": err.Error()}
+		}
+		if err := validateProxyTransform(config.ProxyTransform); err != nil {
+			return fiber.Map{"error": "El campo 'proxyTransform' es inválido.", "details": err.Error()}
+		}
+		// El código de estado y el body provienen del upstream
+		if config.ResponseStatusCode == 0 {
+			config.ResponseStatusCode = fiber.StatusOK
+		}
+		if config.ContentType == "" {
+			config.ContentType = "application/json"
+		}
+	default:
+		return fiber.Map{"error": "Modo de respuesta inválido en 'responseMode'. Los valores permitidos son: static, script, proxy."}
+	}
+
+	if config.ResponseStatusCode == 0 && config.ResponseStatusCodeTemplate == "" {
+		return fiber.Map{"error": "El campo 'responseStatusCode' es requerido y no puede ser 0."}
+	}
+	if config.ResponseStatusCodeTemplate != "" && !config.IsTemplate {
+		return fiber.Map{"error": "El campo 'responseStatusCodeTemplate' requiere que 'isTemplate' sea verdadero."}
+	}
+	if config.RawBody {
+		if _, ok := config.ResponseBody.(string); config.IsTemplate || (config.ResponseMode != "" && config.ResponseMode != models.ResponseModeStatic) || !ok {
+			return fiber.Map{"error": "El campo 'rawBody' requiere un mock estático sin plantilla cuyo 'responseBody' sea un string."}
+		}
+	}
+	if config.Base64Body {
+		if !config.RawBody {
+			return fiber.Map{"error": "El campo 'base64Body' requiere que 'rawBody' sea verdadero."}
+		}
+		if _, err := base64.StdEncoding.DecodeString(config.ResponseBody.(string)); err != nil {
+			return fiber.Map{"error": "El 'responseBody' no es base64 válido.", "details": err.Error()}
+		}
+	}
+
+	// Validación de escenarios
+	config.Scenario = strings.TrimSpace(config.Scenario)
+	config.RequiredScenarioState = strings.TrimSpace(config.RequiredScenarioState)
+	config.NewScenarioState = strings.TrimSpace(config.NewScenarioState)
+	if config.Scenario == "" && (config.RequiredScenarioState != "" || config.NewScenarioState != "") {
+		return fiber.Map{"error": "Los campos 'requiredScenarioState' y 'newScenarioState' requieren el campo 'scenario'."}
+	}
+
+	// Validación de los headers de respuesta
+	for name := range config.ResponseHeaders {
+		if strings.TrimSpace(name) == "" {
+			return fiber.Map{"error": "Los nombres de 'responseHeaders' no pueden estar vacíos."}
+		}
 	}
 
 	// Validación de formato de Path
-	var pathRegex = regexp.MustCompile(`^(/[\w.-]*)*(/[\w.-]*)?$`) // Permite /path/to/resource, /resource, etc.
+	var pathRegex = regexp.MustCompile(`^(/(:?[\w.-]*))*$`) // Permite /path/to/resource, /resource, /users/:id, etc.
 	if !pathRegex.MatchString(config.Path) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'path' tiene un formato URL inválido. Ejemplos válidos: /api/v1/users, /hello-world."})
+		return fiber.Map{"error": "El campo 'path' tiene un formato URL inválido. Ejemplos válidos: /api/v1/users, /hello-world, /users/:id."}
 	}
 
 	// Validación de método HTTP valido
@@ -62,7 +164,7 @@ func ConfigureMock(c *fiber.Ctx) error {
 		"TRACE":   true,
 	}
 	if !validMethods[config.Method] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Método HTTP inválido. Los métodos permitidos son: GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS, CONNECT, TRACE."})
+		return fiber.Map{"error": "Método HTTP inválido. Los métodos permitidos son: GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS, CONNECT, TRACE."}
 	}
 
 	// Inicializar mapas vacíos por cualquier cosa
@@ -96,7 +198,7 @@ func ConfigureMock(c *fiber.Ctx) error {
 		}
 	} else {
 		if !validContentTypes[config.ContentType] {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Content-Type inválido. Los Content-Types permitidos son: " + strings.Join(getKeys(validContentTypes), ", ") + "."})
+			return fiber.Map{"error": "Content-Type inválido. Los Content-Types permitidos son: " + strings.Join(getKeys(validContentTypes), ", ") + "."}
 		}
 	}
 
@@ -115,13 +217,13 @@ func ConfigureMock(c *fiber.Ctx) error {
 			// Intentar serializar y deserializar para validar que ResponseBody sea JSON válido
 			rbBytes, err := json.Marshal(config.ResponseBody)
 			if err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El 'responseBody' no pudo ser serializado a JSON.", "details": err.Error()})
+				return fiber.Map{"error": "El 'responseBody' no pudo ser serializado a JSON.", "details": err.Error()}
 			}
 
 			// Intentar deserializar para validar que sea un JSON válido
 			var temp interface{}
 			if err := json.Unmarshal(rbBytes, &temp); err != nil {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El 'responseBody' no es un JSON válido o la estructura no coincide con 'application/json'.", "details": err.Error()})
+				return fiber.Map{"error": "El 'responseBody' no es un JSON válido o la estructura no coincide con 'application/json'.", "details": err.Error()}
 			}
 			config.ResponseBody = temp // Asegurar que ResponseBody sea un objeto JSON válido
 
@@ -132,7 +234,7 @@ func ConfigureMock(c *fiber.Ctx) error {
 				// Intentar convertir a string si no lo es
 				rbBytes, err := json.Marshal(config.ResponseBody)
 				if err != nil {
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El 'responseBody' no pudo ser convertido a string para el 'Content-Type' especificado.", "details": err.Error()})
+					return fiber.Map{"error": "El 'responseBody' no pudo ser convertido a string para el 'Content-Type' especificado.", "details": err.Error()}
 				}
 				config.ResponseBody = string(rbBytes)
 			}
@@ -141,17 +243,54 @@ func ConfigureMock(c *fiber.Ctx) error {
 
 		// Si es una plantilla, ResponseBody debe ser un string
 		if _, ok := config.ResponseBody.(string); !ok {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Si 'isTemplate' es verdadero, 'responseBody' debe ser un string que contenga la plantilla."})
+			return fiber.Map{"error": "Si 'isTemplate' es verdadero, 'responseBody' debe ser un string que contenga la plantilla."}
+		}
+
+		// Validación del motor de plantillas
+		config.TemplateEngine = strings.ToLower(strings.TrimSpace(config.TemplateEngine))
+		switch config.TemplateEngine {
+		case "", models.TemplateEngineGo:
+		case models.TemplateEngineHandlebars:
+			// Validar la sintaxis de las plantillas Handlebars antes de guardarlas
+			for _, src := range append([]string{config.ResponseBody.(string), config.ResponseStatusCodeTemplate}, getValues(config.ResponseHeaders)...) {
+				if _, err := parseHandlebars(src); err != nil {
+					return fiber.Map{"error": "La plantilla Handlebars es inválida.", "details": err.Error()}
+				}
+			}
+		default:
+			return fiber.Map{"error": "Motor de plantillas inválido en 'templateEngine'. Los valores permitidos son: go, handlebars."}
+		}
+
+		// Las plantillas sin versión se consideran heredadas y se migran al contexto actual
+		if config.TemplateVersion == 0 {
+			storage.MigrateTemplateReferences(config)
+		}
+		if config.TemplateVersion != models.CurrentTemplateVersion {
+			return fiber.Map{"error": "Versión de plantilla no soportada en 'templateVersion'.", "details": fmt.Sprintf("La versión actual es %d.", models.CurrentTemplateVersion)}
 		}
 	}
 
-	// Agregar la configuración del mock al almacenamiento
-	err := storage.AddMockConfig(config)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración del mock en el almacenamiento persistente.", "details": err.Error()})
+	// Validación de los callbacks salientes (se evalúan con el motor de plantillas del mock)
+	if err := normalizeCallbacks(config.Callbacks, strings.ToLower(strings.TrimSpace(config.TemplateEngine))); err != nil {
+		return fiber.Map{"error": "La configuración de 'callbacks' es inválida.", "details": err.Error()}
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Configuración de mock guardada exitosamente", "id": config.Id})
+	return nil
+}
+
+// normalizeTags elimina los espacios, las etiquetas vacías y las repetidas, conservando el orden original.
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
 }
 
 // getKeys es una función auxiliar para obtener las claves de un mapa de booleanos
@@ -163,21 +302,195 @@ func getKeys(m map[string]bool) []string {
 	return keys
 }
 
-// GetMockConfigurations maneja la solicitud GET /configure-mock
+// getValues es una función auxiliar para obtener los valores de un mapa de strings
+func getValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// GetMockConfigurations maneja la solicitud GET /configure-mock.
+// Admite filtros (method, pathPrefix, tag, contentType, enabled, q), orden (sort) y paginación por cursor
+// (limit, cursor). La respuesta sigue siendo la lista de mocks; el total de mocks que cumplen los
+// filtros va en el header X-Total-Count y el cursor de la página siguiente en X-Next-Cursor.
 func GetMockConfigurations(c *fiber.Ctx) error {
-	configs := storage.GetAllMockConfigurations()
+	query, err := parseMockQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Parámetros de consulta inválidos", "details": err.Error()})
+	}
+
+	configs, total, next := queryMocks(storage.GetAllMockConfigurations(workspaceOf(c)), query)
+	c.Set("X-Total-Count", strconv.Itoa(total))
+	if next != "" {
+		c.Set("X-Next-Cursor", next)
+	}
 	return c.Status(fiber.StatusOK).JSON(configs)
 }
 
+// GetMockConfiguration maneja la solicitud GET /configure-mock/:id
+func GetMockConfiguration(c *fiber.Ctx) error {
+	config, ok := storage.GetMockConfigByID(workspaceOf(c), c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
+	}
+	c.Set(fiber.HeaderETag, mockETag(config.Revision))
+	return c.Status(fiber.StatusOK).JSON(config)
+}
+
+// ReplaceMockConfiguration maneja la solicitud PUT /configure-mock/:id.
+// Reemplaza la configuración completa con las mismas validaciones que la creación.
+// Si se envía el header If-Match debe coincidir con el ETag actual del mock.
+func ReplaceMockConfiguration(c *fiber.Ctx) error {
+	current, ok := storage.GetMockConfigByID(workspaceOf(c), c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
+	}
+	if status, errBody := checkIfMatch(c, current.Revision); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var config models.MockConfig
+	if err := c.BodyParser(&config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear la configuración del mock", "details": err.Error()})
+	}
+	return updateMockConfiguration(c, current, config)
+}
+
+// PatchMockConfiguration maneja la solicitud PATCH /configure-mock/:id.
+// El body es un JSON Merge Patch (RFC 7386) que se aplica sobre la configuración actual:
+// los campos presentes se reemplazan, los objetos se combinan y un valor null elimina el campo.
+// Si se envía el header If-Match debe coincidir con el ETag actual del mock.
+func PatchMockConfiguration(c *fiber.Ctx) error {
+	current, ok := storage.GetMockConfigByID(workspaceOf(c), c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
+	}
+	if status, errBody := checkIfMatch(c, current.Revision); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	var patch map[string]interface{}
+	if err := json.Unmarshal(c.Body(), &patch); err != nil || patch == nil {
+		details := "se esperaba un objeto JSON"
+		if err != nil {
+			details = err.Error()
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear el parche de la configuración del mock", "details": details})
+	}
+
+	// Aplicar el parche sobre la representación JSON de la configuración actual
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo serializar la configuración actual del mock.", "details": err.Error()})
+	}
+	var document interface{}
+	if err := json.Unmarshal(currentJSON, &document); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo serializar la configuración actual del mock.", "details": err.Error()})
+	}
+	patchedJSON, err := json.Marshal(applyMergePatch(document, patch))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo aplicar el parche a la configuración del mock.", "details": err.Error()})
+	}
+
+	var config models.MockConfig
+	if err := json.Unmarshal(patchedJSON, &config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El parche genera una configuración de mock inválida.", "details": err.Error()})
+	}
+	return updateMockConfiguration(c, current, config)
+}
+
+// updateMockConfiguration valida y guarda una configuración que reemplaza a la configuración actual del mock.
+// La revisión la asigna el almacenamiento; la del body se ignora.
+func updateMockConfiguration(c *fiber.Ctx, current models.MockConfig, config models.MockConfig) error {
+	if config.Id != "" && config.Id != current.Id {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'id' del body no coincide con el ID de la ruta.", "id": current.Id})
+	}
+	config.Id = current.Id
+
+	if errBody := validateMockConfig(&config); errBody != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errBody)
+	}
+
+	saved, err := storage.UpdateMockConfig(workspaceOf(c), config, current.Revision, changeAuthor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrMockNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
+		case errors.Is(err, storage.ErrMockConflict):
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "El mock fue modificado por otra solicitud. Recargue la configuración e intente de nuevo."})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración del mock en el almacenamiento persistente.", "details": err.Error()})
+	}
+	c.Set(fiber.HeaderETag, mockETag(saved.Revision))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Configuración de mock actualizada exitosamente", "id": saved.Id, "revision": saved.Revision})
+}
+
+// mockETag genera el ETag de una configuración de mock a partir de su revisión.
+func mockETag(revision int) string {
+	return `"` + strconv.Itoa(revision) + `"`
+}
+
+// requireIfMatch indica si las modificaciones de mocks deben enviar el header If-Match (REQUIRE_IF_MATCH).
+func requireIfMatch() bool {
+	required, _ := strconv.ParseBool(os.Getenv("REQUIRE_IF_MATCH"))
+	return required
+}
+
+// checkIfMatch verifica el header If-Match contra la revisión actual del mock. Devuelve el código y
+// el cuerpo del error (412 si no coincide con el ETag actual), o nil si coincide. Sin el header el cambio
+// se acepta, salvo que REQUIRE_IF_MATCH lo exija (428).
+func checkIfMatch(c *fiber.Ctx, revision int) (int, fiber.Map) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		if !requireIfMatch() {
+			return 0, nil
+		}
+		return fiber.StatusPreconditionRequired, fiber.Map{"error": "Se requiere el header If-Match con el ETag actual del mock (obtenido con GET /configure-mock/:id)."}
+	}
+	etag := mockETag(revision)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return 0, nil
+		}
+	}
+	return fiber.StatusPreconditionFailed, fiber.Map{"error": "El mock fue modificado por otra solicitud. Recargue la configuración e intente de nuevo.", "etag": etag, "revision": revision}
+}
+
+// changeAuthor identifica a quien modifica un mock para el historial: el header X-Mock-Author
+// o, si no se envía, la IP del cliente.
+func changeAuthor(c *fiber.Ctx) string {
+	if author := strings.TrimSpace(c.Get("X-Mock-Author")); author != "" {
+		return strings.Clone(author)
+	}
+	return c.IP()
+}
+
 // DeleteMockConfiguration maneja la solicitud DELETE /configure-mock/:id
 func DeleteMockConfiguration(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id := strings.Clone(c.Params("id"))
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID del mock es requerido"})
 	}
 
-	if deleted := storage.DeleteMockConfig(id); !deleted {
+	current, ok := storage.GetMockConfigByID(workspaceOf(c), id)
+	if !ok {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
 	}
+	if status, errBody := checkIfMatch(c, current.Revision); errBody != nil {
+		return c.Status(status).JSON(errBody)
+	}
+
+	if err := storage.DeleteMockConfigRevision(workspaceOf(c), id, current.Revision, changeAuthor(c)); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrMockNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
+		case errors.Is(err, storage.ErrMockConflict):
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "El mock fue modificado por otra solicitud. Recargue la configuración e intente de nuevo."})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo eliminar la configuración del mock del almacenamiento persistente.", "details": err.Error()})
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Configuración de mock eliminada exitosamente"})
 }
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	app := fiber.New()
+	app.Put("/", func(c *fiber.Ctx) error {
+		if status, errBody := checkIfMatch(c, 3); errBody != nil {
+			return c.Status(status).JSON(errBody)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		require, ifMatch string
+		want             int
+	}{
+		{"", "", fiber.StatusOK},
+		{"false", "", fiber.StatusOK},
+		{"true", "", fiber.StatusPreconditionRequired},
+		{"", `"3"`, fiber.StatusOK},
+		{"true", `W/"3"`, fiber.StatusOK},
+		{"", `"1", "3"`, fiber.StatusOK},
+		{"", "*", fiber.StatusOK},
+		{"", `"2"`, fiber.StatusPreconditionFailed},
+		{"true", `"2"`, fiber.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Setenv("REQUIRE_IF_MATCH", tt.require)
+		req := httptest.NewRequest("PUT", "/", nil)
+		if tt.ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, tt.ifMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("REQUIRE_IF_MATCH=%q, If-Match %q: código %d, se esperaba %d", tt.require, tt.ifMatch, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EnableMock maneja la solicitud POST /configure-mock/:id/enable
+func EnableMock(c *fiber.Ctx) error {
+	return setMockEnabled(c, true)
+}
+
+// DisableMock maneja la solicitud POST /configure-mock/:id/disable
+func DisableMock(c *fiber.Ctx) error {
+	return setMockEnabled(c, false)
+}
+
+// setMockEnabled habilita o deshabilita un mock sin eliminarlo. Un mock deshabilitado se ignora al buscar
+// coincidencias. El header If-Match es opcional: si se envía, debe coincidir con el ETag actual.
+func setMockEnabled(c *fiber.Ctx, enabled bool) error {
+	current, ok := storage.GetMockConfigByID(workspaceOf(c), c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
+	}
+	expectedRevision := 0
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		if status, errBody := checkIfMatch(c, current.Revision); errBody != nil {
+			return c.Status(status).JSON(errBody)
+		}
+		expectedRevision = current.Revision
+	}
+
+	config, changed, err := storage.SetMockEnabled(workspaceOf(c), current.Id, enabled, expectedRevision, changeAuthor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrMockNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Configuración de mock no encontrada"})
+		case errors.Is(err, storage.ErrMockConflict):
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "El mock fue modificado por otra solicitud. Recargue la configuración e intente de nuevo."})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración del mock en el almacenamiento persistente.", "details": err.Error()})
+	}
+
+	message := "Mock habilitado exitosamente"
+	if !enabled {
+		message = "Mock deshabilitado exitosamente"
+	}
+	c.Set(fiber.HeaderETag, mockETag(config.Revision))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": message, "id": config.Id, "enabled": enabled, "changed": changed, "revision": config.Revision})
+}
+
+// EnableMocksByTag maneja la solicitud POST /configure-mock/tags/:tag/enable
+func EnableMocksByTag(c *fiber.Ctx) error {
+	return setMocksEnabledByTag(c, true)
+}
+
+// DisableMocksByTag maneja la solicitud POST /configure-mock/tags/:tag/disable
+func DisableMocksByTag(c *fiber.Ctx) error {
+	return setMocksEnabledByTag(c, false)
+}
+
+// setMocksEnabledByTag habilita o deshabilita de una vez todos los mocks con una etiqueta.
+func setMocksEnabledByTag(c *fiber.Ctx, enabled bool) error {
+	tag := strings.Clone(c.Params("tag"))
+	matched, changed, err := storage.SetMocksEnabledByTag(workspaceOf(c), tag, enabled, changeAuthor(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración de los mocks en el almacenamiento persistente.", "details": err.Error()})
+	}
+	if len(matched) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No hay mocks con la etiqueta indicada", "tag": tag})
+	}
+
+	ids := make([]string, len(matched))
+	for i, config := range matched {
+		ids[i] = config.Id
+	}
+	message := "Mocks habilitados exitosamente"
+	if !enabled {
+		message = "Mocks deshabilitados exitosamente"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": message, "tag": tag, "enabled": enabled, "mocks": ids, "changed": changed})
+}
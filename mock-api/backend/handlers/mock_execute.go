@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"log"
-	"text/template"
-
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"backend/models"
 	"backend/storage"
@@ -34,204 +38,293 @@ func getMapValue(m map[string]string, key string) string {
 }
 
 // ExecuteMock es el endpoint genérico que intenta hacer coincidir y ejecutar un mock.
-func ExecuteMock(c *fiber.Ctx) error {
-
-	// Extraer información de la solicitud
-	reqPath := c.Path()
-	reqMethod := c.Method()
-	reqQueryParams := c.Queries()
+func ExecuteMock(c *fiber.Ctx) (err error) {
+	start := time.Now()
+
+	// Extraer información de la solicitud (path, método, query params, headers, cookies y body)
+	req := extractRequestData(c)
+
+	// Registrar la solicitud en el journal del workspace al terminar, con quién la respondió
+	journal := models.JournalEntry{HandledBy: models.JournalHandlerNone}
+	defer func() {
+		recordJournalEntry(c, req, journal, start, err)
+	}()
+	reqPath := req.Path
+	reqMethod := req.Method
+	reqQueryParams := req.Query
+	reqHeaders := req.Headers
+	reqBody := req.Body
 	log.Printf("Request: Path=%s, Method=%s, QueryParams=%v", reqPath, reqMethod, reqQueryParams)
-
-	// Extraer los headers de la solicitud
-	reqHeaders := make(map[string]string)
-	c.Request().Header.VisitAll(func(key, value []byte) {
-		reqHeaders[strings.ToLower(string(key))] = string(value)
-	})
 	log.Printf("Request Headers: %v", reqHeaders)
+	if reqBody != nil {
+		log.Printf("Request Body: %v", reqBody)
+	}
+
+	// Los recursos CRUD, el proxy y las grabaciones son globales y se administran desde el workspace por
+	// defecto, por lo que solo atienden sus solicitudes: un workspace no ve ni graba las de los demás
+	workspace := workspaceOf(c)
+	global := workspace == storage.DefaultWorkspace
 
-	// Extraer el body de la solicitud
-	var reqBody models.RequestBody
-	if len(c.Body()) > 0 && strings.Contains(strings.ToLower(c.Get("Content-Type")), "application/json") {
-		if err := json.Unmarshal(c.Body(), &reqBody); err != nil {
-			log.Printf("Advertencia: No se pudo parsear el cuerpo JSON de la solicitud para %s %s: %v", reqMethod, reqPath, err)
-			// Continuar sin el body parseado si hay error que puede ser un JSON mal formado
-		} else {
-			log.Printf("Request Body: %v", reqBody)
+	// Durante una grabación todas las solicitudes se reenvían al destino para capturar sus respuestas
+	if global {
+		if handled, err := serveRecording(c, req); handled {
+			journal.HandledBy = models.JournalHandlerRecording
+			return err
 		}
 	}
 
 	// Obtener todas las configuraciones de mocks desde el almacenamiento ya ordenadas por prioridad
-	allConfigs := storage.GetAllMockConfigurations()
+	allConfigs := storage.GetAllMockConfigurations(workspace)
 	log.Printf("Total mocks: %d", len(allConfigs))
 
 	// Iterar sobre las configuraciones de mocks para encontrar una coincidencia
 	for _, config := range allConfigs {
-		log.Printf("--- Checkeando mock ID: %s ---", config.Id)
-		log.Printf("Mock Config: Path=%s, Method=%s, QueryParams=%v, BodyParams=%v, Headers=%v, IsTemplate=%t",
-			config.Path, config.Method, config.QueryParams, config.BodyParams, config.Headers, config.IsTemplate)
-
-		// 1. Coincidencia de Ruta y Método
-		// Normaliza la ruta de la solicitud para la comparación
-		if !matchPath(reqPath, config.Path) || !matchMethod(reqMethod, config.Method) {
-			log.Printf("Saltar mock %s: Path '%s' (request) != '%s' (config) OR Method '%s' (request) != '%s' (config)",
-				config.Id, reqPath, config.Path, reqMethod, config.Method)
-			continue
-		}
-		log.Printf("Path y Method coincidencia mock %s.", config.Id)
-
-		// 2. Coincidencia de Query Params
-		if !matchQueryParams(reqQueryParams, config.QueryParams) {
-			log.Printf("QueryParams mismatch mock %s. Request Query: %v, Config Query: %v", config.Id, reqQueryParams, config.QueryParams)
+		result := evaluateMock(workspace, config, req, false)
+		if criterion, failed := failedCriterion(result); failed {
+			log.Printf("Saltar mock %s: no coincide '%s' %+v", config.Id, criterion.Name, criterion.Mismatches)
 			continue
 		}
-		log.Printf("QueryParams coincidencia mock %s.", config.Id)
-
-		// 3. Coincidencia de Body Params
-		if !matchBodyParams(reqBody, config.BodyParams) {
-			log.Printf("BodyParams no coincidencia mock %s. Request Body: %v, Config Body: %v", config.Id, reqBody, config.BodyParams)
-			continue
-		}
-		log.Printf("BodyParams coincidencia mock %s.", config.Id)
-
-		// 4. Coincidencia de Headers
-		if !matchHeaders(reqHeaders, config.Headers) {
-			log.Printf("Headers no coincidencia mock %s. Request Headers: %v, Config Headers: %v", config.Id, reqHeaders, config.Headers)
-			continue
-		}
-		log.Printf("Headers coincidencia mock %s.", config.Id)
+		pathParams := result.PathParams
+		journal.Matched, journal.MockId, journal.HandledBy = true, config.Id, models.JournalHandlerMock
 
 		// Si se llega aquí, encontramos una coincidencia.
 		// Ahora, procesamos la respuesta, incluyendo las plantillas.
+		err := sendMockResponse(c, config, req, pathParams)
 
-		c.Set("Content-Type", config.ContentType)
-		finalResponseBody := config.ResponseBody
-
-		if config.IsTemplate {
-			// Si es una plantilla, necesitamos procesarla
-			templateString, ok := config.ResponseBody.(string)
-			if !ok {
-				// Si IsTemplate es true, pero ResponseBody no es un string, error
-				log.Printf("Error: ResponseBody no es un string a pesar de IsTemplate=true para mock %s", config.Id)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Configuración de mock inválida: el cuerpo de la plantilla no es un string."})
-			}
+		// Los callbacks se evalúan con la respuesta ya generada y se envían en segundo plano. Si el mock
+		// no pudo generar su respuesta (p. ej. por un error de plantilla) no se notifica nada.
+		if err == nil && c.Locals(mockResponseFailedKey) == nil && len(config.Callbacks) > 0 {
+			triggerCallbacks(c, config, req, pathParams)
+		}
 
-			// Crear un nuevo template y parsear la plantilla
-			// Usamos jsonMarshal y getMapValue para poder acceder a los valores dentro de la plantilla
-			tmpl, err := template.New("response").Funcs(template.FuncMap{
-				"json":        jsonMarshal,
-				"getMapValue": getMapValue,
-			}).Parse(templateString)
-			if err != nil {
-				log.Printf("Error al parsear plantilla de mock %s: %v", config.Id, err)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error al procesar la plantilla de respuesta.", "details": err.Error()})
+		// Transición del escenario después de generar la respuesta
+		if config.Scenario != "" && config.NewScenarioState != "" {
+			if storage.TransitionScenario(workspace, config.Scenario, config.RequiredScenarioState, config.NewScenarioState) {
+				log.Printf("Escenario '%s' cambió al estado '%s' por el mock %s", config.Scenario, config.NewScenarioState, config.Id)
 			}
+		}
+		return err
+	}
 
-			// Prepara los datos que estarán disponibles para la plantilla
-			templateData := fiber.Map{
-				"Request": fiber.Map{
-					"Path":    reqPath,
-					"Method":  reqMethod,
-					"Query":   reqQueryParams,
-					"Headers": reqHeaders,
-					"Body":    reqBody, // Este será un map[string]interface{}
-				},
-			}
+	// Si ningún mock coincide, se intenta con los recursos CRUD declarados
+	if global {
+		if handled, err := serveResource(c, req); handled {
+			journal.HandledBy = models.JournalHandlerResource
+			return err
+		}
+	}
 
-			// Ejecutar la plantilla con los datos de la solicitud
-			// Usamos un buffer para capturar la salida de la plantilla
-			var buf bytes.Buffer
-			if err := tmpl.Execute(&buf, templateData); err != nil {
-				log.Printf("Error al ejecutar plantilla de mock %s: %v", config.Id, err)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error al ejecutar la plantilla de respuesta."})
-			}
+	// Si tampoco hay un recurso, se reenvía al upstream configurado (si existe)
+	if global {
+		if handled, err := serveProxy(c, req); handled {
+			journal.HandledBy = models.JournalHandlerProxy
+			return err
+		}
+	}
 
-			// El resultado de la plantilla es un string.
-			// Si el Content-Type es JSON, necesitamos intentar parsearlo de nuevo a interface{}
-			if strings.Contains(strings.ToLower(config.ContentType), "application/json") {
-				var parsedTemplateBody interface{}
+	// Si no se encuentra ninguna coincidencia. Con NEAR_MISS_DIAGNOSTICS se agregan los mocks más cercanos
+	// y en qué difieren de la solicitud.
+	notFound := fiber.Map{"error": "Mock no encontrado para la solicitud", "path": reqPath, "method": reqMethod}
+	if limit := nearMissLimit(); limit > 0 {
+		notFound["nearMisses"] = findNearMisses(workspace, allConfigs, req, limit)
+	}
+	return c.Status(fiber.StatusNotFound).JSON(notFound)
+}
 
-				// Intentar deserializar el JSON generado por la plantilla
-				if err := json.Unmarshal(buf.Bytes(), &parsedTemplateBody); err != nil {
-					log.Printf("Advertencia: La salida de la plantilla no es un JSON válido para mock %s: %v", config.Id, err)
-					// Si la salida de la plantilla no es JSON, enviamos como string simple
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "La plantilla de respuesta JSON generó un JSON inválido.", "details": err.Error()})
-				}
-				finalResponseBody = parsedTemplateBody
-			} else {
-				// Si no es JSON, simplemente lo enviamos como string
-				return c.Status(config.ResponseStatusCode).SendString(buf.String())
-			}
+// newTemplateSet crea un conjunto de plantillas con la biblioteca de funciones y todos los partials
+// registrados, de modo que cualquier plantilla pueda usar '{{template "nombre" .}}'.
+// Los partials adicionales (por ejemplo uno que se está validando) reemplazan a los almacenados.
+// Los partials Handlebars no se parsean como Go: invocarlos desde una plantilla Go produce un error.
+func newTemplateSet(funcs template.FuncMap, extra ...models.TemplatePartial) (*template.Template, error) {
+	partials := make(map[string]models.TemplatePartial)
+	for _, partial := range storage.GetAllPartials() {
+		partials[partial.Name] = partial
+	}
+	for _, partial := range extra {
+		partials[partial.Name] = partial
+	}
+
+	set := template.New("").Funcs(funcs).Funcs(template.FuncMap{"crossEnginePartial": crossEnginePartial})
+	for name, partial := range partials {
+		src := capitalizeStoreCalls(partial.Template)
+		if engine := partial.PartialEngine(); engine != models.TemplateEngineGo {
+			src = fmt.Sprintf("{{crossEnginePartial %q %q}}", name, engine)
 		}
+		if _, err := set.New(name).Parse(src); err != nil {
+			return nil, fmt.Errorf("partial '%s': %w", name, err)
+		}
+	}
+	return set, nil
+}
 
-		// Enviar la respuesta final como JSON
-		return c.Status(config.ResponseStatusCode).JSON(finalResponseBody)
+// crossEnginePartial es el cuerpo de los partials de otro motor dentro de un conjunto de plantillas Go.
+func crossEnginePartial(name, engine string) (string, error) {
+	return "", fmt.Errorf("el partial '%s' usa el motor '%s' y no puede incluirse desde una plantilla go", name, engine)
+}
+
+// renderTemplate parsea y ejecuta una plantilla con la biblioteca de funciones indicada y los partials registrados.
+func renderTemplate(name, src string, funcs template.FuncMap, data interface{}) (string, error) {
+	set, err := newTemplateSet(funcs)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := set.New(name).Parse(capitalizeStoreCalls(src))
+	if err != nil {
+		return "", err
 	}
 
-	// Si no se encuentra ninguna coincidencia
-	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Mock no encontrado para la solicitud", "path": reqPath, "method": reqMethod})
+	// Usamos un buffer para capturar la salida de la plantilla
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", storeMethodHint(err)
+	}
+	return buf.String(), nil
 }
 
-// matchPath verifica si la ruta de la solicitud coincide con la ruta configurada.
-func matchPath(requestPath, configPath string) bool {
-	return requestPath == configPath
+// storeLowercaseMethodRegex reconoce el error de Go templates al usar los métodos del store en minúsculas
+// sobre un valor que capitalizeStoreCalls no reconoce, por ejemplo una variable ('{{$s.set "k" 1}}').
+var storeLowercaseMethodRegex = regexp.MustCompile(`can't evaluate field (get|set|delete|has) in type handlers\.templateStore`)
+
+// storeMethodHint agrega al error de ejecución una indicación con el nombre correcto del método del store.
+func storeMethodHint(err error) error {
+	m := storeLowercaseMethodRegex.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	method := strings.ToUpper(m[1][:1]) + m[1][1:]
+	return fmt.Errorf("%w (en plantillas go los métodos del store empiezan con mayúscula: store.%s)", err, method)
 }
 
-// matchMethod verifica si el método HTTP de la solicitud coincide con el configurado.
-func matchMethod(requestMethod, configMethod string) bool {
-	return strings.EqualFold(requestMethod, configMethod)
+// mockResponseFailedKey marca en c.Locals que el mock no pudo generar la respuesta configurada.
+const mockResponseFailedKey = "mockResponseFailed"
+
+// sendMockFailure envía una respuesta de error en lugar de la respuesta configurada del mock y lo marca
+// en la solicitud para que no se envíen sus callbacks.
+func sendMockFailure(c *fiber.Ctx, status int, body fiber.Map) error {
+	c.Locals(mockResponseFailedKey, true)
+	return c.Status(status).JSON(body)
 }
 
-// matchQueryParams verifica si los parámetros de la URL de la solicitud
-func matchQueryParams(requestParams, configParams map[string]string) bool {
-	if len(configParams) == 0 {
-		return true // Si no hay parámetros configurados, cualquier query params coinciden
+// sendMockResponse genera y envía la respuesta del mock que coincidió con la solicitud.
+// Si el mock es una plantilla, el código de estado, los headers de respuesta y el body
+// se evalúan con el mismo contexto de la solicitud.
+func sendMockResponse(c *fiber.Ctx, config models.MockConfig, req requestData, pathParams map[string]string) error {
+	// Los mocks en modo script generan toda la respuesta desde JavaScript
+	if config.ResponseMode == models.ResponseModeScript {
+		return sendScriptResponse(c, config, req, pathParams)
+	}
+	// Los mocks en modo proxy reenvían la solicitud y transforman la respuesta real
+	if config.ResponseMode == models.ResponseModeProxy {
+		return sendProxyMockResponse(c, config, req)
 	}
-	for key, value := range configParams {
-		if reqVal, ok := requestParams[key]; !ok || reqVal != value {
-			return false
+
+	statusCode := config.ResponseStatusCode
+	finalResponseBody := config.ResponseBody
+
+	if !config.IsTemplate {
+		for name, value := range config.ResponseHeaders {
+			c.Set(name, value)
 		}
+		c.Set("Content-Type", config.ContentType)
+
+		// Con rawBody (los mocks grabados) el body de texto se envía tal cual, sin serializarlo a JSON
+		if body, ok := finalResponseBody.(string); ok && config.RawBody {
+			// Con base64Body el body se guardó codificado (ej. un body binario grabado)
+			if config.Base64Body {
+				data, err := base64.StdEncoding.DecodeString(body)
+				if err != nil {
+					log.Printf("Error al decodificar el body en base64 del mock %s: %v", config.Id, err)
+					return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "El 'responseBody' en base64 del mock es inválido.", "details": err.Error()})
+				}
+				return c.Status(statusCode).Send(data)
+			}
+			return c.Status(statusCode).SendString(body)
+		}
+
+		// Enviar la respuesta final como JSON
+		return c.Status(statusCode).JSON(finalResponseBody)
 	}
-	return true
-}
 
-// matchBodyParams verifica si los parámetros del body de la solicitud
-func matchBodyParams(requestBody, configBody map[string]interface{}) bool {
-	if len(configBody) == 0 {
-		return true
+	// Si es una plantilla, necesitamos procesarla
+	templateString, ok := config.ResponseBody.(string)
+	if !ok {
+		// Si IsTemplate es true, pero ResponseBody no es un string, error
+		log.Printf("Error: ResponseBody no es un string a pesar de IsTemplate=true para mock %s", config.Id)
+		return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "Configuración de mock inválida: el cuerpo de la plantilla no es un string."})
 	}
-	if len(requestBody) == 0 {
-		return false // Si hay parámetros configurados pero no hay body en la request
+
+	// Prepara los datos que estarán disponibles para la plantilla (contexto versionado).
+	// La misma biblioteca de funciones se comparte entre status, headers y body para que
+	// 'fakeSeed' o 'fakeLocale' se apliquen a toda la respuesta.
+	templateData := buildTemplateContext(req, pathParams, config)
+	funcs := templateFuncMap()
+
+	// El motor de plantillas se elige por mock: Go text/template (por defecto) o Handlebars
+	render := func(name, src string) (string, error) {
+		return renderTemplate(name, src, funcs, templateData)
+	}
+	if config.TemplateEngine == models.TemplateEngineHandlebars {
+		hbsData := toHandlebarsContext(templateData)
+		render = func(_, src string) (string, error) {
+			return renderHandlebars(src, funcs, hbsData)
+		}
 	}
 
-	for key, configVal := range configBody {
-		reqVal, ok := requestBody[key]
-		if !ok {
-			return false // El parámetro configurado no está en el body de la solicitud
+	// 1. Código de estado
+	if config.ResponseStatusCodeTemplate != "" {
+		rendered, err := render("status", config.ResponseStatusCodeTemplate)
+		if err != nil {
+			log.Printf("Error al evaluar la plantilla del código de estado del mock %s: %v", config.Id, err)
+			return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "Error al evaluar la plantilla del código de estado.", "details": err.Error()})
 		}
-		// Comparación de valores (simple por ahora, para tipos básicos)
-		if reqVal != configVal {
-			return false
+		code, err := strconv.Atoi(strings.TrimSpace(rendered))
+		if err != nil || code < 100 || code > 599 {
+			log.Printf("Error: La plantilla del código de estado del mock %s generó un valor inválido: %q", config.Id, rendered)
+			return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "La plantilla del código de estado generó un valor inválido.", "details": rendered})
 		}
+		statusCode = code
+	}
+
+	// 2. Headers de respuesta
+	headers := make(map[string]string, len(config.ResponseHeaders))
+	for name, value := range config.ResponseHeaders {
+		rendered, err := render("header", value)
+		if err != nil {
+			log.Printf("Error al evaluar la plantilla del header '%s' del mock %s: %v", name, config.Id, err)
+			return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "Error al evaluar la plantilla del header '" + name + "'.", "details": err.Error()})
+		}
+		headers[name] = rendered
 	}
-	return true
-}
 
-// matchHeaders verifica si los encabezados de la solicitud
-func matchHeaders(requestHeaders, configHeaders map[string]string) bool {
-	if len(configHeaders) == 0 {
-		return true
+	// 3. Body
+	// Usamos la biblioteca de funciones para poder acceder a los valores dentro de la plantilla
+	body, err := render("response", templateString)
+	if err != nil {
+		log.Printf("Error al procesar la plantilla de mock %s: %v", config.Id, err)
+		return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "Error al procesar la plantilla de respuesta.", "details": err.Error()})
 	}
-	if len(requestHeaders) == 0 {
-		return false // Si hay headers configurados pero no hay headers en la request
+
+	for name, value := range headers {
+		c.Set(name, value)
 	}
+	c.Set("Content-Type", config.ContentType)
 
-	for key, value := range configHeaders {
+	// El resultado de la plantilla es un string.
+	// Si el Content-Type es JSON, necesitamos intentar parsearlo de nuevo a interface{}
+	if strings.Contains(strings.ToLower(config.ContentType), "application/json") {
+		var parsedTemplateBody interface{}
 
-		// Normalizar a minúsculas para la comparación de claves
-		reqVal, ok := requestHeaders[strings.ToLower(key)]
-		if !ok || reqVal != value {
-			return false
+		// Intentar deserializar el JSON generado por la plantilla
+		if err := json.Unmarshal([]byte(body), &parsedTemplateBody); err != nil {
+			log.Printf("Advertencia: La salida de la plantilla no es un JSON válido para mock %s: %v", config.Id, err)
+			return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "La plantilla de respuesta JSON generó un JSON inválido.", "details": err.Error()})
 		}
+		finalResponseBody = parsedTemplateBody
+	} else {
+		// Si no es JSON, simplemente lo enviamos como string
+		return c.Status(statusCode).SendString(body)
 	}
-	return true
+
+	// Enviar la respuesta final como JSON
+	return c.Status(statusCode).JSON(finalResponseBody)
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExplainMatch maneja la solicitud POST /configure-mock/explain.
+// Recibe una solicitud de ejemplo y devuelve, para cada mock del workspace en orden de prioridad, qué criterios
+// se cumplen y cuáles no (con el valor esperado y el recibido), y qué mock respondería. No ejecuta ningún mock
+// ni cambia el estado de los escenarios.
+func ExplainMatch(c *fiber.Ctx) error {
+	var sample models.ExplainRequest
+	if err := c.BodyParser(&sample); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear la solicitud de ejemplo", "details": err.Error()})
+	}
+	req, err := explainRequestData(c, sample)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "La solicitud de ejemplo es inválida", "details": err.Error()})
+	}
+
+	workspace := workspaceOf(c)
+	configs := storage.GetAllMockConfigurations(workspace)
+	results := make([]models.MockMatchResult, len(configs))
+	var selected *string
+	for i, config := range configs {
+		results[i] = evaluateMock(workspace, config, req, true)
+		if results[i].Matched && selected == nil {
+			results[i].Selected = true
+			selected = &results[i].MockId
+		}
+	}
+
+	// Los recursos CRUD, el proxy y las grabaciones solo atienden al workspace por defecto
+	global := workspace == storage.DefaultWorkspace
+	message := "Ningún mock coincide con la solicitud; se devolvería 404."
+	if global {
+		message = "Ningún mock coincide con la solicitud. Se intentaría con los recursos CRUD y el proxy; si tampoco responden, se devolvería 404."
+	}
+	if selected != nil {
+		message = fmt.Sprintf("La solicitud sería atendida por el mock '%s'.", *selected)
+	}
+	if recording, active := storage.ActiveRecording(); active && global {
+		message = fmt.Sprintf("Hay una grabación en curso: la solicitud se reenviaría a '%s' sin evaluar los mocks.", recording.Target)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"workspace": workspace,
+		"request":   fiber.Map{"method": req.Method, "path": req.Path, "query": req.Query, "headers": req.Headers, "body": req.Body},
+		"selected":  selected,
+		"message":   message,
+		"mocks":     results,
+	})
+}
+
+// explainRequestData convierte la solicitud de ejemplo en los mismos datos que extractRequestData obtiene
+// de una solicitud real. El query string del path se combina con 'query' (que tiene precedencia) y los
+// nombres de los headers se normalizan a minúsculas.
+func explainRequestData(c *fiber.Ctx, sample models.ExplainRequest) (requestData, error) {
+	req := requestData{
+		Method:   strings.ToUpper(strings.TrimSpace(sample.Method)),
+		Query:    make(map[string]string),
+		QueryAll: make(map[string][]string),
+		Headers:  make(map[string]string),
+		Cookies:  make(map[string]string),
+		ClientIP: strings.Clone(c.IP()),
+	}
+	if req.Method == "" {
+		return req, fmt.Errorf("el campo 'method' es obligatorio")
+	}
+
+	path, rawQuery, _ := strings.Cut(strings.TrimSpace(sample.Path), "?")
+	if !strings.HasPrefix(path, "/") {
+		return req, fmt.Errorf("el campo 'path' es obligatorio y debe comenzar con '/'")
+	}
+	req.Path = path
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return req, fmt.Errorf("query string inválido: %w", err)
+	}
+	for key, all := range values {
+		req.Query[key] = all[0]
+		req.QueryAll[key] = all
+	}
+	for key, value := range sample.Query {
+		req.Query[key] = value
+		req.QueryAll[key] = []string{value}
+	}
+
+	for key, value := range sample.Headers {
+		req.Headers[strings.ToLower(key)] = value
+	}
+
+	// Body: un objeto se serializa como lo enviaría un cliente JSON (con ese Content-Type si no se indica otro).
+	// Igual que en extractRequestData, el body solo se parsea si el Content-Type es JSON.
+	switch body := sample.Body.(type) {
+	case nil:
+	case string:
+		req.RawBody = body
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return req, fmt.Errorf("no se pudo serializar el body: %w", err)
+		}
+		req.RawBody = string(raw)
+		if _, ok := req.Headers["content-type"]; !ok {
+			req.Headers["content-type"] = "application/json"
+		}
+	}
+	if req.RawBody != "" && isJSONContentType(req.Headers["content-type"]) {
+		if !json.Valid([]byte(req.RawBody)) {
+			return req, fmt.Errorf("el body no es un JSON válido y el Content-Type indica JSON")
+		}
+		// Un JSON que no es un objeto (ej. una lista) queda sin parsear, como en una solicitud real
+		_ = json.Unmarshal([]byte(req.RawBody), &req.Body)
+	}
+	return req, nil
+}
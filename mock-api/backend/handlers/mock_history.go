@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetMockHistory maneja la solicitud GET /configure-mock/:id/history.
+// Devuelve las revisiones del mock, de la más reciente a la más antigua, con autor, fecha,
+// campos modificados y la configuración de cada revisión. También funciona para mocks eliminados.
+func GetMockHistory(c *fiber.Ctx) error {
+	history, ok := storage.GetMockHistory(workspaceOf(c), c.Params("id"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No hay historial para el mock indicado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(history)
+}
+
+// RollbackMockConfiguration maneja la solicitud POST /configure-mock/:id/rollback/:revision.
+// Restaura la configuración que tenía el mock en esa revisión y la guarda como una revisión nueva.
+// Si el mock existe y se envía el header If-Match, debe coincidir con su ETag actual.
+func RollbackMockConfiguration(c *fiber.Ctx) error {
+	id := strings.Clone(c.Params("id"))
+	revision, err := strconv.Atoi(c.Params("revision"))
+	if err != nil || revision < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "La revisión debe ser un entero positivo.", "revision": c.Params("revision")})
+	}
+
+	expectedRevision := 0
+	if current, ok := storage.GetMockConfigByID(workspaceOf(c), id); ok {
+		if status, errBody := checkIfMatch(c, current.Revision); errBody != nil {
+			return c.Status(status).JSON(errBody)
+		}
+		expectedRevision = current.Revision
+	}
+
+	config, err := storage.RollbackMockConfig(workspaceOf(c), id, revision, expectedRevision, changeAuthor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrMockNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No hay historial para el mock indicado"})
+		case errors.Is(err, storage.ErrRevisionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "La revisión no existe en el historial del mock o corresponde a una eliminación.", "revision": revision})
+		case errors.Is(err, storage.ErrMockConflict):
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{"error": "El mock fue modificado por otra solicitud. Recargue la configuración e intente de nuevo."})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración del mock en el almacenamiento persistente.", "details": err.Error()})
+	}
+
+	c.Set(fiber.HeaderETag, mockETag(config.Revision))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Configuración de mock restaurada exitosamente", "id": config.Id, "revision": config.Revision, "rollbackOf": revision})
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Modos de importación de un conjunto de mocks
+const (
+	importModeMerge   = "merge"
+	importModeReplace = "replace"
+	importModeDryRun  = "dry-run"
+)
+
+// mockSetFormat obtiene el formato ("json" o "yaml") de un documento de mocks a partir del query param
+// 'format' o, si no se indica, del header dado (Content-Type al importar, Accept al exportar).
+func mockSetFormat(c *fiber.Ctx, header string) (string, error) {
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = "json"
+		if strings.Contains(strings.ToLower(c.Get(header)), "yaml") {
+			format = "yaml"
+		}
+	}
+	switch format {
+	case "json":
+		return format, nil
+	case "yaml", "yml":
+		return "yaml", nil
+	}
+	return "", fmt.Errorf("formato '%s' no soportado. Formatos válidos: json, yaml", format)
+}
+
+// ExportMocks maneja la solicitud GET /configure-mock/export.
+// Devuelve todos los mocks como un solo documento JSON o YAML (?format=yaml o 'Accept: application/yaml'),
+// ordenados por ID para que el archivo sea estable entre exportaciones.
+func ExportMocks(c *fiber.Ctx) error {
+	format, err := mockSetFormat(c, fiber.HeaderAccept)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	mocks := storage.GetAllMockConfigurations(workspaceOf(c))
+	sort.Slice(mocks, func(i, j int) bool {
+		return mocks[i].Id < mocks[j].Id
+	})
+	set := models.MockSet{Version: models.MockSetVersion, ExportedAt: time.Now().UTC(), Mocks: mocks}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudieron serializar los mocks.", "details": err.Error()})
+	}
+	if format == "yaml" {
+		// Se convierte desde JSON para que los nombres de los campos sean los mismos en ambos formatos
+		var document interface{}
+		if err := json.Unmarshal(data, &document); err == nil {
+			data, err = yaml.Marshal(document)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudieron serializar los mocks.", "details": err.Error()})
+		}
+		c.Set(fiber.HeaderContentType, "application/yaml")
+	} else {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="mocks.%s"`, format))
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// parseMockSetItems extrae los mocks de un documento importado. Se aceptan tres formas: el documento
+// de exportación ('{ "mocks": [...] }'), una lista de mocks o el formato de mocks.json (un objeto por ID).
+func parseMockSetItems(data []byte, format string) ([]interface{}, error) {
+	var document interface{}
+	var err error
+	if format == "yaml" {
+		err = yaml.Unmarshal(data, &document)
+	} else {
+		err = json.Unmarshal(data, &document)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch doc := document.(type) {
+	case []interface{}:
+		return doc, nil
+	case map[string]interface{}:
+		if mocks, ok := doc["mocks"]; ok {
+			items, ok := mocks.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("el campo 'mocks' debe ser una lista")
+			}
+			return items, nil
+		}
+		// Formato de mocks.json: el ID es la clave de cada configuración
+		ids := make([]string, 0, len(doc))
+		for id := range doc {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		items := make([]interface{}, 0, len(doc))
+		for _, id := range ids {
+			if item, ok := doc[id].(map[string]interface{}); ok && item["id"] == nil {
+				item["id"] = id
+			}
+			items = append(items, doc[id])
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("se esperaba un objeto con el campo 'mocks', una lista de mocks o un objeto de mocks por ID")
+}
+
+// parseImportedMock convierte un elemento del documento importado en una configuración válida, con las
+// mismas reglas que ConfigureMock. Devuelve el resultado con el error si el mock es inválido.
+func parseImportedMock(index int, item interface{}) (models.MockConfig, *models.MockImportResult) {
+	var config models.MockConfig
+	invalid := &models.MockImportResult{Index: index, Action: models.ImportActionInvalid}
+
+	data, err := json.Marshal(item)
+	if err == nil {
+		if _, ok := item.(map[string]interface{}); !ok {
+			err = fmt.Errorf("se esperaba un objeto y se recibió %T", item)
+		} else {
+			err = json.Unmarshal(data, &config)
+		}
+	}
+	if err != nil {
+		invalid.Error = "No se pudo parsear la configuración del mock"
+		invalid.Details = err.Error()
+		return config, invalid
+	}
+
+	if config.Id == "" {
+		config.Id = uuid.New().String()
+	}
+	invalid.Id = config.Id
+	if errBody := validateMockConfig(&config); errBody != nil {
+		invalid.Error, _ = errBody["error"].(string)
+		delete(errBody, "error")
+		if len(errBody) > 0 {
+			invalid.Details = errBody
+		}
+		return config, invalid
+	}
+	return config, nil
+}
+
+// ImportMocks maneja la solicitud POST /configure-mock/import.
+// El body es un documento JSON o YAML (?format=yaml o 'Content-Type: application/yaml') con un conjunto de mocks.
+// Modos (?mode=): 'merge' (por defecto) crea y reemplaza los mocks del documento sin tocar el resto,
+// 'replace' además elimina los mocks que no están en el documento y 'dry-run' informa qué haría 'merge'
+// sin guardar nada (?dryRun=true hace lo mismo con cualquier modo).
+// Cada mock se valida igual que en ConfigureMock; si alguno es inválido no se importa ninguno.
+func ImportMocks(c *fiber.Ctx) error {
+	mode := strings.ToLower(c.Query("mode", importModeMerge))
+	if mode != importModeMerge && mode != importModeReplace && mode != importModeDryRun {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Modo de importación '%s' no soportado. Modos válidos: merge, replace, dry-run.", mode)})
+	}
+	dryRun := mode == importModeDryRun || c.QueryBool("dryRun")
+
+	format, err := mockSetFormat(c, fiber.HeaderContentType)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	items, err := parseMockSetItems(c.Body(), format)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear el documento de mocks", "format": format, "details": err.Error()})
+	}
+
+	// Validar todos los mocks antes de guardar cualquiera
+	configs := make([]models.MockConfig, 0, len(items))
+	var invalid []models.MockImportResult
+	seen := make(map[string]int, len(items))
+	for i, item := range items {
+		config, result := parseImportedMock(i, item)
+		if result == nil {
+			if first, exists := seen[config.Id]; exists {
+				result = &models.MockImportResult{Index: i, Id: config.Id, Action: models.ImportActionInvalid, Error: fmt.Sprintf("El ID está repetido en el documento (elemento %d).", first)}
+			}
+		}
+		if result != nil {
+			invalid = append(invalid, *result)
+			continue
+		}
+		seen[config.Id] = i
+		configs = append(configs, config)
+	}
+	if len(invalid) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   fmt.Sprintf("%d de %d mocks son inválidos. No se importó ningún mock.", len(invalid), len(items)),
+			"results": invalid,
+		})
+	}
+
+	results, deleted, err := storage.ImportMockConfigs(workspaceOf(c), configs, mode == importModeReplace, dryRun, changeAuthor(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración de los mocks en el almacenamiento persistente.", "details": err.Error()})
+	}
+
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Action]++
+	}
+	message := "Mocks importados exitosamente"
+	if dryRun {
+		message = "Simulación de importación completada. No se guardaron cambios."
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":   message,
+		"mode":      mode,
+		"dryRun":    dryRun,
+		"created":   counts[models.HistoryActionCreate],
+		"updated":   counts[models.HistoryActionUpdate],
+		"unchanged": counts[models.ImportActionUnchanged],
+		"deleted":   deleted,
+		"results":   results,
+	})
+}
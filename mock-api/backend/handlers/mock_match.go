@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/models"
+	"backend/storage"
+)
+
+// evaluateMock evalúa los criterios de un mock contra una solicitud en el mismo orden que ExecuteMock:
+// habilitado, path, método, query params, body params, headers y estado del escenario.
+// Con full en falso se detiene en el primer criterio que falla, que es lo que necesita ExecuteMock;
+// con full en verdadero evalúa todos para poder explicar por qué la solicitud coincide o no.
+func evaluateMock(workspace string, config models.MockConfig, req requestData, full bool) models.MockMatchResult {
+	result := models.MockMatchResult{MockId: config.Id, Path: config.Path, Method: config.Method, Priority: config.Priority, Matched: true}
+	check := func(name string, mismatches []models.MatchMismatch) bool {
+		passed := len(mismatches) == 0
+		result.Criteria = append(result.Criteria, models.MatchCriterion{Name: name, Passed: passed, Mismatches: mismatches})
+		result.Matched = result.Matched && passed
+		return passed || full
+	}
+
+	if !check(models.MatchCriterionEnabled, enabledMismatches(config)) {
+		return result
+	}
+	pathParams, pathMatches := matchPath(req.Path, config.Path)
+	var pathMismatches []models.MatchMismatch
+	if !pathMatches {
+		pathMismatches = []models.MatchMismatch{{Field: "path", Expected: config.Path, Actual: req.Path}}
+	}
+	if !check(models.MatchCriterionPath, pathMismatches) {
+		return result
+	}
+	result.PathParams = pathParams
+	if !check(models.MatchCriterionMethod, methodMismatches(req.Method, config.Method)) {
+		return result
+	}
+	if !check(models.MatchCriterionQueryParams, queryParamMismatches(req.Query, config.QueryParams)) {
+		return result
+	}
+	if !check(models.MatchCriterionBodyParams, bodyParamMismatches(req.Body, config.BodyParams)) {
+		return result
+	}
+	if !check(models.MatchCriterionHeaders, headerMismatches(req.Headers, config.Headers)) {
+		return result
+	}
+	check(models.MatchCriterionScenario, scenarioMismatches(workspace, config))
+	return result
+}
+
+// failedCriterion devuelve el primer criterio que no se cumplió en una evaluación.
+func failedCriterion(result models.MockMatchResult) (models.MatchCriterion, bool) {
+	for _, criterion := range result.Criteria {
+		if !criterion.Passed {
+			return criterion, true
+		}
+	}
+	return models.MatchCriterion{}, false
+}
+
+// defaultNearMissLimit es la cantidad de mocks cercanos que se incluyen en la respuesta 404.
+// Se puede ajustar con la variable de entorno NEAR_MISS_LIMIT.
+const defaultNearMissLimit = 3
+
+// nearMissLimit devuelve cuántos mocks cercanos se incluyen en la respuesta 404 cuando ninguno coincide.
+// El diagnóstico está desactivado (0) salvo que NEAR_MISS_DIAGNOSTICS sea verdadero.
+func nearMissLimit() int {
+	if enabled, _ := strconv.ParseBool(os.Getenv("NEAR_MISS_DIAGNOSTICS")); !enabled {
+		return 0
+	}
+	if v, err := strconv.Atoi(os.Getenv("NEAR_MISS_LIMIT")); err == nil && v > 0 {
+		return v
+	}
+	return defaultNearMissLimit
+}
+
+// findNearMisses evalúa todos los criterios de cada mock y devuelve los más cercanos a la solicitud, con las
+// diferencias que impidieron la coincidencia. Solo se consideran los mocks que coinciden en path o en método;
+// primero los que coinciden en path, luego en método y, entre ellos, los que tienen menos diferencias.
+// El orden de prioridad desempata.
+func findNearMisses(workspace string, configs []models.MockConfig, req requestData, limit int) []models.NearMiss {
+	type candidate struct {
+		nearMiss     models.NearMiss
+		pathFailed   bool
+		methodFailed bool
+	}
+	var candidates []candidate
+	for _, config := range configs {
+		result := evaluateMock(workspace, config, req, true)
+		if result.Matched {
+			continue
+		}
+		entry := candidate{nearMiss: models.NearMiss{MockId: config.Id, Path: config.Path, Method: config.Method, Priority: config.Priority}}
+		for _, criterion := range result.Criteria {
+			switch {
+			case criterion.Passed:
+				continue
+			case criterion.Name == models.MatchCriterionPath:
+				entry.pathFailed = true
+			case criterion.Name == models.MatchCriterionMethod:
+				entry.methodFailed = true
+			}
+			for _, mismatch := range criterion.Mismatches {
+				entry.nearMiss.Differences = append(entry.nearMiss.Differences, models.MatchDifference{Criterion: criterion.Name, MatchMismatch: mismatch})
+			}
+		}
+		if entry.pathFailed && entry.methodFailed {
+			continue
+		}
+		candidates = append(candidates, entry)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.pathFailed != b.pathFailed {
+			return !a.pathFailed
+		}
+		if a.methodFailed != b.methodFailed {
+			return !a.methodFailed
+		}
+		return len(a.nearMiss.Differences) < len(b.nearMiss.Differences)
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	nearMisses := make([]models.NearMiss, len(candidates))
+	for i, entry := range candidates {
+		nearMisses[i] = entry.nearMiss
+	}
+	return nearMisses
+}
+
+// matchPath verifica si la ruta de la solicitud coincide con la ruta configurada.
+// Los segmentos de la forma ':nombre' en la ruta configurada aceptan cualquier valor
+// y se devuelven como parámetros de ruta.
+func matchPath(requestPath, configPath string) (map[string]string, bool) {
+	if !strings.Contains(configPath, ":") {
+		return nil, requestPath == configPath
+	}
+
+	reqSegments := strings.Split(requestPath, "/")
+	configSegments := strings.Split(configPath, "/")
+	if len(reqSegments) != len(configSegments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range configSegments {
+		if strings.HasPrefix(segment, ":") && len(segment) > 1 {
+			if reqSegments[i] == "" {
+				return nil, false // Un parámetro de ruta no puede estar vacío
+			}
+			params[segment[1:]] = reqSegments[i]
+			continue
+		}
+		if segment != reqSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// enabledMismatches verifica que el mock no esté deshabilitado.
+func enabledMismatches(config models.MockConfig) []models.MatchMismatch {
+	if config.IsEnabled() {
+		return nil
+	}
+	return []models.MatchMismatch{{Field: "enabled", Expected: true, Actual: false}}
+}
+
+// methodMismatches verifica si el método HTTP de la solicitud coincide con el configurado.
+func methodMismatches(requestMethod, configMethod string) []models.MatchMismatch {
+	if strings.EqualFold(requestMethod, configMethod) {
+		return nil
+	}
+	return []models.MatchMismatch{{Field: "method", Expected: configMethod, Actual: requestMethod}}
+}
+
+// scenarioMismatches verifica si el escenario del mock está en el estado requerido.
+func scenarioMismatches(workspace string, config models.MockConfig) []models.MatchMismatch {
+	if config.Scenario == "" || config.RequiredScenarioState == "" {
+		return nil
+	}
+	state := storage.GetScenarioState(workspace, config.Scenario)
+	if state == config.RequiredScenarioState {
+		return nil
+	}
+	return []models.MatchMismatch{{Field: config.Scenario, Expected: config.RequiredScenarioState, Actual: state}}
+}
+
+// queryParamMismatches verifica que la solicitud tenga cada query param configurado con el mismo valor.
+// Si no hay parámetros configurados, cualquier query params coinciden.
+func queryParamMismatches(requestParams, configParams map[string]string) []models.MatchMismatch {
+	var mismatches []models.MatchMismatch
+	for _, key := range sortedKeys(configParams) {
+		value := configParams[key]
+		if reqVal, ok := requestParams[key]; !ok {
+			mismatches = append(mismatches, models.MatchMismatch{Field: key, Expected: value, Missing: true})
+		} else if reqVal != value {
+			mismatches = append(mismatches, models.MatchMismatch{Field: key, Expected: value, Actual: reqVal})
+		}
+	}
+	return mismatches
+}
+
+// bodyParamMismatches verifica que el body JSON de la solicitud tenga cada parámetro configurado con el mismo valor.
+// Un objeto o arreglo configurado debe ser igual al de la solicitud, sin elementos ni campos de más.
+func bodyParamMismatches(requestBody, configBody map[string]interface{}) []models.MatchMismatch {
+	keys := make([]string, 0, len(configBody))
+	for key := range configBody {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var mismatches []models.MatchMismatch
+	for _, key := range keys {
+		configVal := configBody[key]
+		reqVal, ok := requestBody[key]
+		if !ok {
+			// El parámetro configurado no está en el body de la solicitud (o no hay body)
+			mismatches = append(mismatches, models.MatchMismatch{Field: key, Expected: configVal, Missing: true})
+			continue
+		}
+		// Los objetos y arreglos se comparan completos: ambos lados provienen de JSON, por lo que tienen
+		// los mismos tipos (map[string]interface{}, []interface{}, float64, string, bool o nil)
+		if !reflect.DeepEqual(reqVal, configVal) {
+			mismatches = append(mismatches, models.MatchMismatch{Field: key, Expected: configVal, Actual: reqVal})
+		}
+	}
+	return mismatches
+}
+
+// headerMismatches verifica que la solicitud tenga cada header configurado con el mismo valor.
+// Los nombres de los headers se comparan sin distinguir mayúsculas.
+func headerMismatches(requestHeaders, configHeaders map[string]string) []models.MatchMismatch {
+	var mismatches []models.MatchMismatch
+	for _, key := range sortedKeys(configHeaders) {
+		value := configHeaders[key]
+		if reqVal, ok := requestHeaders[strings.ToLower(key)]; !ok {
+			mismatches = append(mismatches, models.MatchMismatch{Field: key, Expected: value, Missing: true})
+		} else if reqVal != value {
+			mismatches = append(mismatches, models.MatchMismatch{Field: key, Expected: value, Actual: reqVal})
+		}
+	}
+	return mismatches
+}
+
+// sortedKeys devuelve las claves de un mapa ordenadas, para que los resultados sean estables.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
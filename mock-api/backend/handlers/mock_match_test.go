@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBodyParamMismatches(t *testing.T) {
+	// Los bodyParams y el body se decodifican de JSON, igual que al guardar el mock y al recibir la solicitud
+	tests := []struct {
+		params, body string
+		want         []string
+	}{
+		{`{"id": 7}`, `{"id": 7, "extra": true}`, nil},
+		{`{"id": 7}`, `{"id": "7"}`, []string{"id"}},
+		{`{"customer": {"id": "c1", "tags": ["vip"]}}`, `{"customer": {"id": "c1", "tags": ["vip"]}}`, nil},
+		{`{"customer": {"id": "c1"}}`, `{"customer": {"id": "c2"}}`, []string{"customer"}},
+		{`{"customer": {"id": "c1"}}`, `{"customer": {"id": "c1", "name": "Ana"}}`, []string{"customer"}},
+		{`{"items": [1, 2]}`, `{"items": [2, 1]}`, []string{"items"}},
+		{`{"items": []}`, `{"items": {}}`, []string{"items"}},
+		{`{"items": [{"sku": "a"}], "note": null}`, `{"items": [{"sku": "a"}], "note": null}`, nil},
+		{`{"items": [1], "zip": "x"}`, `{}`, []string{"items", "zip"}},
+	}
+	for _, tt := range tests {
+		var params, body map[string]interface{}
+		if err := json.Unmarshal([]byte(tt.params), &params); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.body), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		mismatches := bodyParamMismatches(body, params)
+		var fields []string
+		for _, m := range mismatches {
+			fields = append(fields, m.Field)
+		}
+		if len(fields) != len(tt.want) {
+			t.Errorf("bodyParams %s con body %s: diferencias en %v, se esperaban en %v", tt.params, tt.body, fields, tt.want)
+			continue
+		}
+		for i := range fields {
+			if fields[i] != tt.want[i] {
+				t.Errorf("bodyParams %s con body %s: diferencias en %v, se esperaban en %v", tt.params, tt.body, fields, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// defaultProxyTimeout es el tiempo máximo de espera de una solicitud reenviada.
+// Se puede ajustar con la variable de entorno PROXY_TIMEOUT_MS.
+const defaultProxyTimeout = 30 * time.Second
+
+// proxyViaToken se agrega al header Via de las solicitudes reenviadas para detectar ciclos
+// (por ejemplo, un upstream que apunta al propio servidor de mocks). Es único por instancia
+// para permitir encadenar varios servidores de mocks.
+var proxyViaToken = "mock-api-" + uuid.New().String()[:8]
+
+// hopByHopHeaders son los headers propios de cada conexión que no deben reenviarse.
+var hopByHopHeaders = map[string]bool{
+	"connection":          true,
+	"keep-alive":          true,
+	"proxy-authenticate":  true,
+	"proxy-authorization": true,
+	"te":                  true,
+	"trailer":             true,
+	"transfer-encoding":   true,
+	"upgrade":             true,
+	"host":                true,
+	"content-length":      true,
+}
+
+// proxyClient es el cliente HTTP compartido por las solicitudes reenviadas. No sigue redirecciones
+// para que el cliente original reciba la respuesta del upstream tal cual.
+var proxyClient = &http.Client{
+	Timeout: proxyTimeout(),
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// proxyTimeout devuelve el tiempo máximo configurado para las solicitudes reenviadas.
+func proxyTimeout() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("PROXY_TIMEOUT_MS")); err == nil && v > 0 {
+		return time.Duration(v) * time.Millisecond
+	}
+	return defaultProxyTimeout
+}
+
+// proxiedResponse es la respuesta obtenida del upstream, copiada para poder transformarla o grabarla.
+type proxiedResponse struct {
+	Status  int
+	Headers http.Header
+	Body    []byte
+}
+
+// validateUpstreamURL valida que un upstream sea una URL absoluta http o https.
+func validateUpstreamURL(upstream string) error {
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' debe ser una URL absoluta http o https", upstream)
+	}
+	return nil
+}
+
+// forwardRequest reenvía la solicitud actual a upstream + path (conservando el query string, los headers
+// y el body) y devuelve la respuesta completa del upstream.
+func forwardRequest(c *fiber.Ctx, req requestData, upstream, path string) (*proxiedResponse, error) {
+	target := strings.TrimSuffix(upstream, "/") + path
+	if query := string(c.Request().URI().QueryString()); query != "" {
+		target += "?" + query
+	}
+
+	outgoing, err := http.NewRequest(req.Method, target, bytes.NewReader([]byte(req.RawBody)))
+	if err != nil {
+		return nil, err
+	}
+	// Accept-Encoding no se reenvía: así el cliente HTTP de Go negocia gzip por su cuenta y entrega el body
+	// ya descomprimido (sin Content-Encoding ni Content-Length), que es lo que necesitan las transformaciones
+	// y las grabaciones
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if lower := strings.ToLower(name); !hopByHopHeaders[lower] && lower != "accept-encoding" {
+			outgoing.Header.Add(name, string(value))
+		}
+	})
+	outgoing.Header.Set("X-Forwarded-For", req.ClientIP)
+	outgoing.Header.Set("X-Forwarded-Host", string(c.Request().Host()))
+	outgoing.Header.Set("X-Forwarded-Proto", c.Protocol())
+	outgoing.Header.Add("Via", "1.1 "+proxyViaToken)
+
+	response, err := proxyClient.Do(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var reader io.Reader = response.Body
+	// Un upstream puede comprimir aunque no se le haya pedido; gzip se descomprime aquí por el mismo motivo
+	if strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("body gzip inválido: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+		response.Header.Del("Content-Encoding")
+		response.Header.Del("Content-Length")
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &proxiedResponse{Status: response.StatusCode, Headers: response.Header, Body: body}, nil
+}
+
+// sendProxiedResponse envía al cliente una respuesta obtenida del upstream.
+func sendProxiedResponse(c *fiber.Ctx, resp *proxiedResponse) error {
+	for name, values := range resp.Headers {
+		if hopByHopHeaders[strings.ToLower(name)] {
+			continue
+		}
+		for i, value := range values {
+			if i == 0 {
+				c.Set(name, value)
+			} else {
+				c.Append(name, value)
+			}
+		}
+	}
+	return c.Status(resp.Status).Send(resp.Body)
+}
+
+// isProxyLoop indica si la solicitud ya pasó por este servidor como proxy.
+func isProxyLoop(req requestData) bool {
+	return strings.Contains(req.Headers["via"], proxyViaToken)
+}
+
+// serveProxy reenvía una solicitud sin mock al upstream configurado (global o por prefijo de ruta).
+// Devuelve false si no hay un upstream para la ruta.
+func serveProxy(c *fiber.Ctx, req requestData) (bool, error) {
+	upstream, path, ok := storage.ResolveProxyUpstream(req.Path)
+	if !ok {
+		return false, nil
+	}
+	if isProxyLoop(req) {
+		return true, c.Status(fiber.StatusLoopDetected).JSON(fiber.Map{"error": "Se detectó un ciclo de proxy: la solicitud ya fue reenviada por este servidor.", "upstream": upstream})
+	}
+
+	resp, err := forwardRequest(c, req, upstream, path)
+	if err != nil {
+		log.Printf("Error al reenviar %s %s a '%s': %v", req.Method, req.Path, upstream, err)
+		return true, c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "No se pudo contactar el servicio upstream.", "upstream": upstream, "details": err.Error()})
+	}
+	log.Printf("Solicitud %s %s reenviada a '%s' -> %d", req.Method, req.Path, upstream, resp.Status)
+	return true, sendProxiedResponse(c, resp)
+}
+
+// applyProxyTransform modifica la respuesta del upstream según la transformación del mock:
+// primero el código de estado y los headers, y después el body (JSON Patch y luego JSON Merge Patch).
+func applyProxyTransform(resp *proxiedResponse, transform *models.ProxyTransform) error {
+	if transform == nil {
+		return nil
+	}
+	if transform.Status != 0 {
+		resp.Status = transform.Status
+	}
+	for _, name := range transform.RemoveHeaders {
+		resp.Headers.Del(name)
+	}
+	for name, value := range transform.SetHeaders {
+		resp.Headers.Set(name, value)
+	}
+
+	if len(transform.JSONPatch) == 0 && transform.MergePatch == nil {
+		return nil
+	}
+	var body interface{}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return fmt.Errorf("la respuesta del upstream no es un JSON válido: %w", err)
+	}
+	if len(transform.JSONPatch) > 0 {
+		patched, err := applyJSONPatch(body, transform.JSONPatch)
+		if err != nil {
+			return fmt.Errorf("JSON Patch: %w", err)
+		}
+		body = patched
+	}
+	if transform.MergePatch != nil {
+		body = applyMergePatch(body, deepCopyJSON(transform.MergePatch))
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp.Body = data
+	resp.Headers.Set("Content-Type", "application/json")
+	return nil
+}
+
+// sendProxyMockResponse reenvía la solicitud de un mock con responseMode "proxy" a su upstream
+// (proxyTo) y devuelve la respuesta transformada.
+func sendProxyMockResponse(c *fiber.Ctx, config models.MockConfig, req requestData) error {
+	if isProxyLoop(req) {
+		return sendMockFailure(c, fiber.StatusLoopDetected, fiber.Map{"error": "Se detectó un ciclo de proxy: la solicitud ya fue reenviada por este servidor.", "upstream": config.ProxyTo})
+	}
+
+	resp, err := forwardRequest(c, req, config.ProxyTo, req.Path)
+	if err != nil {
+		log.Printf("Error al reenviar %s %s a '%s' para el mock %s: %v", req.Method, req.Path, config.ProxyTo, config.Id, err)
+		return sendMockFailure(c, fiber.StatusBadGateway, fiber.Map{"error": "No se pudo contactar el servicio upstream.", "upstream": config.ProxyTo, "details": err.Error()})
+	}
+	if err := applyProxyTransform(resp, config.ProxyTransform); err != nil {
+		log.Printf("Error al transformar la respuesta del upstream para el mock %s: %v", config.Id, err)
+		return sendMockFailure(c, fiber.StatusBadGateway, fiber.Map{"error": "No se pudo transformar la respuesta del upstream.", "upstream": config.ProxyTo, "details": err.Error()})
+	}
+	return sendProxiedResponse(c, resp)
+}
+
+// validateProxyTransform valida la transformación de un mock con responseMode "proxy".
+func validateProxyTransform(transform *models.ProxyTransform) error {
+	if transform == nil {
+		return nil
+	}
+	if transform.Status != 0 && (transform.Status < 100 || transform.Status > 599) {
+		return fmt.Errorf("'status' debe ser un entero entre 100 y 599")
+	}
+	for name := range transform.SetHeaders {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("los nombres de 'setHeaders' no pueden estar vacíos")
+		}
+	}
+	if err := validateJSONPatch(transform.JSONPatch); err != nil {
+		return fmt.Errorf("'jsonPatch': %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Límites de la paginación de GET /configure-mock
+const (
+	maxMockPageSize = 500
+	defaultMockSort = "-priority"
+)
+
+// mockSortFields son los campos por los que se puede ordenar el listado de mocks.
+var mockSortFields = map[string]bool{"priority": true, "id": true, "path": true, "method": true, "revision": true}
+
+// mockQuery reúne los filtros, el orden y la paginación del listado de mocks.
+type mockQuery struct {
+	Methods     []string
+	PathPrefix  string
+	Tags        []string
+	ContentType string
+	Enabled     *bool
+	Search      string
+	Sort        string
+	Limit       int
+	After       *models.MockConfig
+}
+
+// mockCursor es el contenido del cursor opaco de paginación: el orden usado y la clave del último mock
+// devuelto. La página siguiente empieza después de esa clave, por lo que crear o eliminar mocks entre
+// páginas no repite ni salta elementos.
+type mockCursor struct {
+	Sort     string `json:"sort"`
+	Limit    int    `json:"limit"`
+	Id       string `json:"id"`
+	Priority int    `json:"priority,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Method   string `json:"method,omitempty"`
+	Revision int    `json:"revision,omitempty"`
+}
+
+// splitQueryList une los valores repetidos de un query param y separa los que vienen separados por comas.
+func splitQueryList(c *fiber.Ctx, name string) []string {
+	var values []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(name) {
+		for _, value := range strings.Split(string(raw), ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// parseMockQuery lee los query params del listado de mocks.
+func parseMockQuery(c *fiber.Ctx) (mockQuery, error) {
+	query := mockQuery{
+		PathPrefix:  c.Query("pathPrefix"),
+		Tags:        splitQueryList(c, "tag"),
+		ContentType: strings.ToLower(strings.TrimSpace(c.Query("contentType"))),
+		Search:      strings.ToLower(strings.TrimSpace(c.Query("q"))),
+		Sort:        strings.TrimSpace(c.Query("sort")),
+	}
+	for _, method := range splitQueryList(c, "method") {
+		query.Methods = append(query.Methods, strings.ToUpper(method))
+	}
+
+	if enabled := c.Query("enabled"); enabled != "" {
+		value, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return query, fmt.Errorf("'enabled' debe ser true o false")
+		}
+		query.Enabled = &value
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxMockPageSize {
+			return query, fmt.Errorf("'limit' debe ser un entero entre 1 y %d", maxMockPageSize)
+		}
+		query.Limit = value
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		var cursor mockCursor
+		data, err := base64.RawURLEncoding.DecodeString(raw)
+		if err == nil {
+			err = json.Unmarshal(data, &cursor)
+		}
+		if err != nil || cursor.Id == "" {
+			return query, fmt.Errorf("el cursor es inválido")
+		}
+		if query.Sort != "" && query.Sort != cursor.Sort {
+			return query, fmt.Errorf("el cursor se generó con sort=%s y no puede usarse con sort=%s", cursor.Sort, query.Sort)
+		}
+		query.Sort = cursor.Sort
+		if query.Limit == 0 {
+			query.Limit = cursor.Limit
+		}
+		query.After = &models.MockConfig{Id: cursor.Id, Priority: cursor.Priority, Path: cursor.Path, Method: cursor.Method, Revision: cursor.Revision}
+	}
+
+	if query.Sort == "" {
+		query.Sort = defaultMockSort
+	}
+	if !mockSortFields[strings.TrimPrefix(query.Sort, "-")] {
+		return query, fmt.Errorf("no se puede ordenar por '%s'. Campos válidos: priority, id, path, method, revision (con '-' para orden descendente)", query.Sort)
+	}
+	return query, nil
+}
+
+// encodeMockCursor genera el cursor de la página que sigue al mock indicado.
+func encodeMockCursor(query mockQuery, last models.MockConfig) string {
+	data, _ := json.Marshal(mockCursor{
+		Sort:     query.Sort,
+		Limit:    query.Limit,
+		Id:       last.Id,
+		Priority: last.Priority,
+		Path:     last.Path,
+		Method:   last.Method,
+		Revision: last.Revision,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// compareMocks compara dos mocks según el campo de orden ('-' al inicio para orden descendente).
+// Los empates se resuelven por ID para que el orden sea estable entre páginas.
+func compareMocks(a, b models.MockConfig, sortBy string) int {
+	field := strings.TrimPrefix(sortBy, "-")
+	result := 0
+	switch field {
+	case "priority":
+		result = a.Priority - b.Priority
+	case "revision":
+		result = a.Revision - b.Revision
+	case "path":
+		result = strings.Compare(a.Path, b.Path)
+	case "method":
+		result = strings.Compare(a.Method, b.Method)
+	}
+	if strings.HasPrefix(sortBy, "-") {
+		result = -result
+	}
+	if result == 0 {
+		result = strings.Compare(a.Id, b.Id)
+		if field == "id" && strings.HasPrefix(sortBy, "-") {
+			result = -result
+		}
+	}
+	return result
+}
+
+// matchesMockQuery indica si un mock cumple todos los filtros de la consulta.
+func matchesMockQuery(config models.MockConfig, query mockQuery) bool {
+	if len(query.Methods) > 0 && !slices.Contains(query.Methods, config.Method) {
+		return false
+	}
+	if query.PathPrefix != "" && !strings.HasPrefix(config.Path, query.PathPrefix) {
+		return false
+	}
+	for _, tag := range query.Tags {
+		if !slices.Contains(config.Tags, tag) {
+			return false
+		}
+	}
+	if query.ContentType != "" && !strings.HasPrefix(strings.ToLower(config.ContentType), query.ContentType) {
+		return false
+	}
+	if query.Enabled != nil && config.IsEnabled() != *query.Enabled {
+		return false
+	}
+	if query.Search != "" && !strings.Contains(mockSearchText(config), query.Search) {
+		return false
+	}
+	return true
+}
+
+// mockSearchText arma el texto en minúsculas sobre el que se hace la búsqueda libre: ID, ruta, método,
+// etiquetas, content type, body de respuesta, script y destino del proxy.
+func mockSearchText(config models.MockConfig) string {
+	parts := []string{config.Id, config.Path, config.Method, config.ContentType, config.Script, config.ProxyTo}
+	parts = append(parts, config.Tags...)
+	if body, ok := config.ResponseBody.(string); ok {
+		parts = append(parts, body)
+	} else if data, err := json.Marshal(config.ResponseBody); err == nil {
+		parts = append(parts, string(data))
+	}
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
+
+// queryMocks filtra y ordena los mocks, y devuelve la página pedida, el total de mocks que cumplen
+// los filtros y el cursor de la página siguiente ("" si es la última).
+func queryMocks(configs []models.MockConfig, query mockQuery) ([]models.MockConfig, int, string) {
+	filtered := make([]models.MockConfig, 0, len(configs))
+	for _, config := range configs {
+		if matchesMockQuery(config, query) {
+			filtered = append(filtered, config)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return compareMocks(filtered[i], filtered[j], query.Sort) < 0
+	})
+	total := len(filtered)
+
+	if query.After != nil {
+		start := sort.Search(len(filtered), func(i int) bool {
+			return compareMocks(filtered[i], *query.After, query.Sort) > 0
+		})
+		filtered = filtered[start:]
+	}
+	if query.Limit == 0 || len(filtered) <= query.Limit {
+		return filtered, total, ""
+	}
+	page := filtered[:query.Limit]
+	return page, total, encodeMockCursor(query, page[len(page)-1])
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// listedMocks es el conjunto de mocks sobre el que se prueban los filtros y el orden del listado.
+func listedMocks() []models.MockConfig {
+	disabled := false
+	return []models.MockConfig{
+		{Id: "a", Path: "/users", Method: "GET", Priority: 1, Revision: 3, ContentType: "application/json", Tags: []string{"users", "smoke"}, ResponseBody: map[string]interface{}{"name": "Ana"}},
+		{Id: "b", Path: "/users/:id", Method: "DELETE", Priority: 5, Revision: 1, ContentType: "application/json", Tags: []string{"users"}},
+		{Id: "c", Path: "/orders", Method: "POST", Priority: 1, Revision: 2, ContentType: "text/plain", ResponseBody: "Pedido creado", Enabled: &disabled},
+		{Id: "d", Path: "/orders/:id", Method: "GET", Priority: 0, Revision: 1, ContentType: "application/json", Tags: []string{"smoke"}, ResponseMode: models.ResponseModeProxy, ProxyTo: "http://staging.local"},
+	}
+}
+
+// joinMockIds une los IDs de los mocks con comas para compararlos fácilmente.
+func joinMockIds(configs []models.MockConfig) string {
+	ids := make([]string, len(configs))
+	for i, config := range configs {
+		ids[i] = config.Id
+	}
+	return strings.Join(ids, ",")
+}
+
+// parseQueryString ejecuta parseMockQuery sobre una solicitud GET con el query string indicado.
+func parseQueryString(t *testing.T, rawQuery string) (query mockQuery, err error) {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		query, err = parseMockQuery(c)
+		return nil
+	})
+	resp, testErr := app.Test(httptest.NewRequest("GET", "/?"+rawQuery, nil))
+	if testErr != nil {
+		t.Fatal(testErr)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return query, err
+}
+
+func TestQueryMocks(t *testing.T) {
+	// Query string y los IDs esperados, en orden
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "b,a,c,d"},
+		{"sort=id&method=get,post", "a,c,d"},
+		{"sort=id&pathPrefix=/users", "a,b"},
+		{"sort=id&tag=users&tag=smoke", "a"},
+		{"sort=id&contentType=TEXT/", "c"},
+		{"sort=id&enabled=true", "a,b,d"},
+		{"sort=id&enabled=false", "c"},
+		{"sort=id&q=ANA", "a"},
+		{"sort=id&q=staging", "d"},
+		{"sort=path", "c,d,a,b"},
+		{"sort=-id", "d,c,b,a"},
+		{"sort=-revision", "a,c,b,d"},
+		{"sort=id&q=nada", ""},
+	}
+	for _, tt := range tests {
+		query, err := parseQueryString(t, tt.query)
+		if err != nil {
+			t.Errorf("%q: error inesperado: %v", tt.query, err)
+			continue
+		}
+		page, total, cursor := queryMocks(listedMocks(), query)
+		if got := joinMockIds(page); got != tt.want {
+			t.Errorf("%q = %q, se esperaba %q", tt.query, got, tt.want)
+		}
+		if total != len(page) || cursor != "" {
+			t.Errorf("%q: total %d y cursor %q en una sola página", tt.query, total, cursor)
+		}
+	}
+}
+
+func TestQueryMocksPagination(t *testing.T) {
+	for _, sortBy := range []string{"-priority", "path", "-id"} {
+		configs := listedMocks()
+		all, _, _ := queryMocks(configs, mockQuery{Sort: sortBy})
+
+		var pages []string
+		rawQuery := "limit=3&sort=" + url.QueryEscape(sortBy)
+		for rawQuery != "" {
+			if len(pages) > len(configs) {
+				t.Fatalf("sort=%s: la paginación no termina", sortBy)
+			}
+			query, err := parseQueryString(t, rawQuery)
+			if err != nil {
+				t.Fatalf("sort=%s: error inesperado: %v", sortBy, err)
+			}
+			page, _, cursor := queryMocks(configs, query)
+			pages = append(pages, joinMockIds(page))
+			rawQuery = ""
+			if cursor != "" {
+				rawQuery = "cursor=" + cursor
+			}
+
+			// Un mock creado entre páginas que queda antes del cursor no repite ni salta elementos
+			if len(pages) == 1 {
+				configs = append(configs, models.MockConfig{Id: "z", Path: "/", Method: "GET", Priority: 9})
+			}
+		}
+		if got, want := strings.Join(pages, ","), joinMockIds(all); got != want {
+			t.Errorf("sort=%s: páginas %v, se esperaba %s", sortBy, pages, want)
+		}
+	}
+}
+
+func TestParseMockQueryErrors(t *testing.T) {
+	cursor := encodeMockCursor(mockQuery{Sort: "path", Limit: 2}, models.MockConfig{Id: "a", Path: "/users"})
+	tests := map[string]string{
+		"enabled=quizás":           "'enabled' debe ser true o false",
+		"limit=0":                  "'limit' debe ser un entero",
+		"limit=501":                "'limit' debe ser un entero",
+		"sort=body":                "no se puede ordenar por 'body'",
+		"cursor=xyz":               "el cursor es inválido",
+		"sort=id&cursor=" + cursor: "se generó con sort=path",
+	}
+	for rawQuery, want := range tests {
+		if _, err := parseQueryString(t, rawQuery); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: se esperaba un error con %q, se obtuvo %v", rawQuery, want, err)
+		}
+	}
+
+	// El cursor conserva el orden y el tamaño de página de la consulta que lo generó
+	query, err := parseQueryString(t, "cursor="+cursor)
+	if err != nil || query.Sort != "path" || query.Limit != 2 || query.After == nil || query.After.Id != "a" {
+		t.Errorf("consulta con cursor = %+v (%v)", query, err)
+	}
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/dop251/goja"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Límites por defecto para la ejecución de scripts. Se pueden ajustar con las variables
+// de entorno SCRIPT_TIMEOUT_MS y SCRIPT_MEMORY_LIMIT_MB.
+const (
+	defaultScriptTimeout     = 1000 * time.Millisecond
+	defaultScriptMemoryLimit = 32 << 20 // 32 MB
+	scriptMaxCallStackSize   = 1024
+)
+
+// scriptValueSize es el costo estimado en bytes de cada elemento de un arreglo o argumento de un script.
+const scriptValueSize = 16
+
+// scriptHeapSampleInterval es cada cuánto se mide el heap mientras corre un script.
+const scriptHeapSampleInterval = time.Millisecond
+
+// scriptHeapMetric es la métrica del runtime con los bytes ocupados por objetos del heap (incluye la basura
+// que aún no se recolecta). Leerla no detiene el programa, a diferencia de runtime.ReadMemStats.
+const scriptHeapMetric = "/memory/classes/heap/objects:bytes"
+
+// scriptRemovedGlobals son los constructores que permiten reservar memoria binaria arbitraria y que los
+// scripts de mocks no necesitan. Se eliminan de la VM en lugar de contabilizarse.
+var scriptRemovedGlobals = []string{
+	"ArrayBuffer", "SharedArrayBuffer", "DataView", "Int8Array", "Uint8Array", "Uint8ClampedArray",
+	"Int16Array", "Uint16Array", "Int32Array", "Uint32Array", "Float32Array", "Float64Array",
+	"BigInt64Array", "BigUint64Array",
+}
+
+// scriptResult es la respuesta devuelta por un script: '{ status, headers, body }'.
+type scriptResult struct {
+	Status  int
+	Headers map[string]string
+	Body    interface{}
+}
+
+// scriptLimits devuelve el tiempo máximo de ejecución y el límite de memoria configurados.
+func scriptLimits() (time.Duration, uint64) {
+	timeout := defaultScriptTimeout
+	if v, err := strconv.Atoi(os.Getenv("SCRIPT_TIMEOUT_MS")); err == nil && v > 0 {
+		timeout = time.Duration(v) * time.Millisecond
+	}
+	memory := uint64(defaultScriptMemoryLimit)
+	if v, err := strconv.Atoi(os.Getenv("SCRIPT_MEMORY_LIMIT_MB")); err == nil && v > 0 {
+		memory = uint64(v) << 20
+	}
+	return timeout, memory
+}
+
+// wrapScript envuelve el código del usuario en una función para permitir 'return' en el nivel superior.
+func wrapScript(src string) string {
+	return "(function(request, mock, server, helpers) {\n" + src + "\n})"
+}
+
+// compileScript valida la sintaxis de un script sin ejecutarlo.
+func compileScript(src string) error {
+	_, err := goja.Compile("mock-script", wrapScript(src), true)
+	return err
+}
+
+// scriptMemory lleva la cuenta de la memoria reservada por las funciones nativas de una VM. Cada VM tiene
+// su propio presupuesto, de modo que las demás solicitudes no cuentan contra el límite del script.
+type scriptMemory struct {
+	vm    *goja.Runtime
+	limit int64
+	used  int64
+}
+
+// charge suma bytes al presupuesto de la VM. Si se supera el límite interrumpe la ejecución y devuelve false,
+// para que la función nativa no llegue a reservar la memoria.
+func (m *scriptMemory) charge(bytes int64) bool {
+	if bytes < 0 || bytes > m.limit-m.used {
+		m.used = m.limit
+		m.vm.Interrupt(fmt.Sprintf("se superó el límite de memoria (%d MB)", m.limit>>20))
+		return false
+	}
+	m.used += bytes
+	return true
+}
+
+// wrap reemplaza el método de un prototipo por uno que cobra su costo antes de llamar al original.
+// cost recibe 'this' y los argumentos; se evalúa antes de reservar la memoria, por lo que una sola llamada
+// enorme (por ejemplo 'x'.repeat(1e9)) se rechaza sin ejecutarse. Con after, el costo se calcula con el
+// resultado ya creado (para métodos cuyo tamaño no se conoce antes, como join).
+func (m *scriptMemory) wrap(proto *goja.Object, method string, cost func(call goja.FunctionCall, result goja.Value) int64, after bool) {
+	original, ok := goja.AssertFunction(proto.Get(method))
+	if !ok {
+		return
+	}
+	proto.Set(method, func(call goja.FunctionCall) goja.Value {
+		if !after && !m.charge(cost(call, nil)) {
+			return goja.Undefined()
+		}
+		result, err := original(call.This, call.Arguments...)
+		if interrupted, ok := err.(*goja.InterruptedError); ok {
+			// La interrupción se consumió dentro del método original; se vuelve a pedir para detener el script
+			m.vm.Interrupt(interrupted.Value())
+			return goja.Undefined()
+		}
+		if err != nil {
+			panic(err)
+		}
+		if after && !m.charge(cost(call, result)) {
+			return goja.Undefined()
+		}
+		return result
+	})
+}
+
+// limitScriptMemory instala el presupuesto de memoria en la VM: elimina los buffers binarios y cobra la
+// memoria de los métodos nativos que crean strings o arreglos a partir de un tamaño indicado por el script,
+// para rechazar una reserva enorme antes de hacerla. El resto de la memoria (concatenación con '+', objetos
+// y arreglos literales) la controla watchScriptHeap.
+func limitScriptMemory(vm *goja.Runtime, limit uint64) {
+	m := &scriptMemory{vm: vm, limit: int64(limit)}
+	for _, name := range scriptRemovedGlobals {
+		vm.GlobalObject().Delete(name)
+	}
+
+	argInt := func(call goja.FunctionCall, i int) int64 {
+		if n := call.Argument(i).ToFloat(); n > 0 {
+			if n > float64(m.limit) {
+				return m.limit + 1
+			}
+			return int64(n)
+		}
+		return 0
+	}
+	thisLength := func(call goja.FunctionCall) int64 {
+		return call.This.ToObject(vm).Get("length").ToInteger()
+	}
+	resultSize := func(_ goja.FunctionCall, result goja.Value) int64 {
+		if obj, ok := result.(*goja.Object); ok {
+			return obj.Get("length").ToInteger() * scriptValueSize
+		}
+		return int64(len(result.String()))
+	}
+
+	stringProto := vm.Get("String").ToObject(vm).Get("prototype").ToObject(vm)
+	m.wrap(stringProto, "repeat", func(call goja.FunctionCall, _ goja.Value) int64 {
+		count := argInt(call, 0)
+		if count > m.limit {
+			return -1
+		}
+		return int64(len(call.This.String())) * count
+	}, false)
+	for _, method := range []string{"padStart", "padEnd"} {
+		m.wrap(stringProto, method, func(call goja.FunctionCall, _ goja.Value) int64 { return argInt(call, 0) }, false)
+	}
+	for _, method := range []string{"concat", "replace", "replaceAll", "split"} {
+		m.wrap(stringProto, method, resultSize, true)
+	}
+
+	arrayCtor := vm.Get("Array").ToObject(vm)
+	arrayProto := arrayCtor.Get("prototype").ToObject(vm)
+	m.wrap(arrayProto, "fill", func(call goja.FunctionCall, _ goja.Value) int64 {
+		return thisLength(call) * scriptValueSize
+	}, false)
+	m.wrap(arrayCtor, "from", func(call goja.FunctionCall, _ goja.Value) int64 {
+		if source, ok := call.Argument(0).(*goja.Object); ok {
+			return source.Get("length").ToInteger() * scriptValueSize
+		}
+		return 0
+	}, false)
+	for _, method := range []string{"push", "unshift"} {
+		m.wrap(arrayProto, method, func(call goja.FunctionCall, _ goja.Value) int64 {
+			return int64(len(call.Arguments)) * scriptValueSize
+		}, false)
+	}
+	for _, method := range []string{"concat", "join", "map", "slice", "splice"} {
+		m.wrap(arrayProto, method, resultSize, true)
+	}
+	m.wrap(vm.Get("JSON").ToObject(vm), "stringify", resultSize, true)
+}
+
+// heapObjectBytes devuelve los bytes ocupados actualmente por objetos del heap del proceso.
+func heapObjectBytes() int64 {
+	sample := []metrics.Sample{{Name: scriptHeapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return int64(sample[0].Value.Uint64())
+}
+
+// watchScriptHeap mide el heap del proceso mientras corre el script e interrumpe la VM si crece más que el
+// límite. Como la medición incluye basura, al pasar el umbral se fuerza una recolección y solo se interrumpe
+// si la memoria sigue en uso; la siguiente recolección forzada ocurre recién después de otro límite de
+// reservas. La medición es del proceso: con solicitudes simultáneas también cuentan las reservas de las demás.
+// Devuelve una función que detiene la medición.
+func watchScriptHeap(vm *goja.Runtime, limit uint64) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	baseline := heapObjectBytes()
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(scriptHeapSampleInterval)
+		defer ticker.Stop()
+		next := baseline + int64(limit)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if heapObjectBytes() <= next {
+				continue
+			}
+			runtime.GC()
+			live := heapObjectBytes()
+			if live-baseline > int64(limit) {
+				vm.Interrupt(fmt.Sprintf("se superó el límite de memoria (%d MB)", limit>>20))
+				return
+			}
+			next = live + int64(limit)
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// runMockScript ejecuta el script de un mock en una VM aislada. El script recibe 'request', 'mock', 'server'
+// (el mismo contexto que Handlebars) y 'helpers' (la biblioteca de funciones de plantillas), y debe
+// devolver un objeto '{ status, headers, body }'.
+//
+// Aislamiento: la VM no expone 'require', sistema de archivos ni red; solo 'console.log' y el store clave-valor ('store').
+// Límites: la ejecución se interrumpe al superar el tiempo máximo o el límite de memoria (ver limitScriptMemory
+// y watchScriptHeap). Un pánico en un helper se devuelve como error.
+func runMockScript(config models.MockConfig, ctx map[string]interface{}, funcs map[string]interface{}) (result scriptResult, err error) {
+	result = scriptResult{Status: config.ResponseStatusCode, Headers: map[string]string{}}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("error interno al ejecutar el script: %v", p)
+		}
+	}()
+
+	program, err := goja.Compile("mock-script", wrapScript(config.Script), true)
+	if err != nil {
+		return result, fmt.Errorf("error de sintaxis: %w", err)
+	}
+
+	vm := goja.New()
+	vm.SetFieldNameMapper(goja.UncapFieldNameMapper())
+	vm.SetMaxCallStackSize(scriptMaxCallStackSize)
+
+	console := vm.NewObject()
+	console.Set("log", func(call goja.FunctionCall) goja.Value {
+		parts := make([]string, len(call.Arguments))
+		for i, arg := range call.Arguments {
+			parts[i] = arg.String()
+		}
+		log.Printf("[script mock %s] %s", config.Id, strings.Join(parts, " "))
+		return goja.Undefined()
+	})
+	vm.Set("console", console)
+	vm.Set("store", scriptStore(vm, storage.DefaultStoreNamespace))
+
+	// Límites de tiempo y de memoria
+	timeout, memoryLimit := scriptLimits()
+	limitScriptMemory(vm, memoryLimit)
+	timer := time.AfterFunc(timeout, func() {
+		vm.Interrupt(fmt.Sprintf("se superó el tiempo máximo de ejecución (%s)", timeout))
+	})
+	defer timer.Stop()
+	defer watchScriptHeap(vm, memoryLimit)()
+
+	fnValue, err := vm.RunProgram(program)
+	if err != nil {
+		return result, err
+	}
+	fn, ok := goja.AssertFunction(fnValue)
+	if !ok {
+		return result, fmt.Errorf("el script no pudo inicializarse")
+	}
+
+	helpers := vm.NewObject()
+	for name, f := range funcs {
+		helpers.Set(name, f)
+	}
+
+	ret, err := fn(goja.Undefined(), vm.ToValue(ctx["request"]), vm.ToValue(ctx["mock"]), vm.ToValue(ctx["server"]), helpers)
+	if err != nil {
+		return result, err
+	}
+
+	return parseScriptResult(ret.Export(), result)
+}
+
+// scriptStore construye el objeto 'store' de los scripts: get (null si la clave no existe), set, delete, has
+// y namespace(nombre) para trabajar sobre otro namespace.
+func scriptStore(vm *goja.Runtime, namespace string) *goja.Object {
+	store := vm.NewObject()
+	store.Set("get", func(key string) interface{} {
+		value, _ := storage.GetStoreValue(namespace, key)
+		return value
+	})
+	store.Set("set", func(key string, value goja.Value) {
+		if err := storage.SetStoreValue(namespace, key, value.Export()); err != nil {
+			panic(vm.NewGoError(fmt.Errorf("store: no se pudo guardar la clave '%s': %w", key, err)))
+		}
+	})
+	store.Set("delete", func(key string) bool {
+		return storage.DeleteStoreValue(namespace, key)
+	})
+	store.Set("has", func(key string) bool {
+		_, ok := storage.GetStoreValue(namespace, key)
+		return ok
+	})
+	store.Set("namespace", func(name string) *goja.Object {
+		if name == "" {
+			name = storage.DefaultStoreNamespace
+		}
+		return scriptStore(vm, name)
+	})
+	return store
+}
+
+// parseScriptResult interpreta el valor devuelto por el script.
+func parseScriptResult(value interface{}, result scriptResult) (scriptResult, error) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return result, fmt.Errorf("el script debe devolver un objeto { status, headers, body } y devolvió %T", value)
+	}
+
+	if status, exists := obj["status"]; exists && status != nil {
+		code, err := toInt(status)
+		if err != nil || code < 100 || code > 599 {
+			return result, fmt.Errorf("'status' debe ser un entero entre 100 y 599")
+		}
+		result.Status = int(code)
+	}
+
+	if headers, exists := obj["headers"]; exists && headers != nil {
+		h, ok := headers.(map[string]interface{})
+		if !ok {
+			return result, fmt.Errorf("'headers' debe ser un objeto")
+		}
+		for name, v := range h {
+			result.Headers[name] = toString(v)
+		}
+	}
+
+	result.Body = obj["body"]
+	return result, nil
+}
+
+// sendScriptResponse ejecuta el script del mock y envía su respuesta.
+func sendScriptResponse(c *fiber.Ctx, config models.MockConfig, req requestData, pathParams map[string]string) error {
+	ctx := toHandlebarsContext(buildTemplateContext(req, pathParams, config))
+
+	result, err := runMockScript(config, ctx, templateFuncMap())
+	if err != nil {
+		log.Printf("Error al ejecutar el script del mock %s: %v", config.Id, err)
+		return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "Error al ejecutar el script del mock.", "details": err.Error()})
+	}
+
+	for name, value := range result.Headers {
+		c.Set(name, value)
+	}
+	if result.Headers["Content-Type"] == "" && result.Headers["content-type"] == "" {
+		c.Set("Content-Type", config.ContentType)
+	}
+
+	// Los strings se envían tal cual; cualquier otro valor se serializa a JSON
+	switch body := result.Body.(type) {
+	case nil:
+		return c.Status(result.Status).Send(nil)
+	case string:
+		return c.Status(result.Status).SendString(body)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return sendMockFailure(c, fiber.StatusInternalServerError, fiber.Map{"error": "El body devuelto por el script no pudo serializarse a JSON.", "details": err.Error()})
+		}
+		return c.Status(result.Status).Send(data)
+	}
+}
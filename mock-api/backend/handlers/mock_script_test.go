@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"backend/models"
+)
+
+// runTestScript ejecuta un script con un contexto vacío. El tiempo máximo se amplía para que las pruebas
+// de memoria no terminen por tiempo.
+func runTestScript(t *testing.T, script string) (scriptResult, error) {
+	t.Helper()
+	t.Setenv("SCRIPT_TIMEOUT_MS", "20000")
+	config := models.MockConfig{Id: "script-test", ResponseStatusCode: 200, Script: script}
+	return runMockScript(config, map[string]interface{}{}, templateFuncMap())
+}
+
+func TestRunMockScript(t *testing.T) {
+	result, err := runTestScript(t, `
+		const items = [];
+		for (let i = 0; i < 1000; i++) items.push({ id: i });
+		return { status: 201, headers: { "X-Total": items.length }, body: { last: items[999].id } };
+	`)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if result.Status != 201 || result.Headers["X-Total"] != "1000" {
+		t.Errorf("resultado inesperado: %+v", result)
+	}
+	if body, ok := result.Body.(map[string]interface{}); !ok || body["last"] != int64(999) {
+		t.Errorf("body inesperado: %#v", result.Body)
+	}
+}
+
+func TestRunMockScriptMemoryLimit(t *testing.T) {
+	scripts := map[string]string{
+		"concatenación que se duplica": `let s = 'x'; for (let i = 0; i < 28; i++) s = s + s; return { body: s.length };`,
+		"objetos en un arreglo":        `const a = []; for (let i = 0; i < 1e8; i++) a[i] = { id: i, name: 'n' + i }; return { body: a.length };`,
+		"objetos en un mapa":           `const o = {}; for (let i = 0; i < 1e8; i++) o['k' + i] = [i, i]; return { body: 1 };`,
+		"repeat enorme":                `return { body: 'x'.repeat(1e9) };`,
+	}
+	for name, script := range scripts {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("SCRIPT_MEMORY_LIMIT_MB", "8")
+			_, err := runTestScript(t, script)
+			if err == nil || !strings.Contains(err.Error(), "se superó el límite de memoria") {
+				t.Fatalf("se esperaba un error de memoria, se obtuvo %v", err)
+			}
+		})
+	}
+}
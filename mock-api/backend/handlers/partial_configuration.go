@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// partialNameRegex define los nombres válidos de partials (ej. errorEnvelope, paging-wrapper).
+var partialNameRegex = regexp.MustCompile(`^[A-Za-z][\w.-]*$`)
+
+// reservedTemplateNames son los nombres que usa internamente la ejecución de mocks.
+var reservedTemplateNames = map[string]bool{
+	"response": true,
+	"status":   true,
+	"header":   true,
+}
+
+// ConfigurePartial maneja la solicitud POST /configure-mock/partials
+func ConfigurePartial(c *fiber.Ctx) error {
+	var partial models.TemplatePartial
+
+	// Parsear el cuerpo de la solicitud a la estructura TemplatePartial
+	if err := c.BodyParser(&partial); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear el partial", "details": err.Error()})
+	}
+	partial.Name = strings.TrimSpace(partial.Name)
+
+	// VALIDACIONES
+	if partial.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'name' es requerido y no puede estar vacío."})
+	}
+	if !partialNameRegex.MatchString(partial.Name) || reservedTemplateNames[partial.Name] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'name' es inválido. Debe iniciar con una letra y contener solo letras, números, '.', '-' o '_' (los nombres 'response', 'status' y 'header' están reservados)."})
+	}
+	if strings.TrimSpace(partial.Template) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'template' es requerido y no puede estar vacío."})
+	}
+
+	partial.Engine = strings.ToLower(strings.TrimSpace(partial.Engine))
+	if partial.Engine != "" && partial.Engine != models.TemplateEngineGo && partial.Engine != models.TemplateEngineHandlebars {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'engine' es inválido. Valores válidos: go, handlebars."})
+	}
+
+	// Validar que el partial sea una plantilla válida con su motor (los Go junto con los partials existentes)
+	var err error
+	if partial.PartialEngine() == models.TemplateEngineHandlebars {
+		_, err = parseHandlebars(partial.Template)
+	} else {
+		_, err = newTemplateSet(templateFuncMap(), partial)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'template' no es una plantilla válida.", "engine": partial.PartialEngine(), "details": err.Error()})
+	}
+
+	_, existed := storage.GetPartial(partial.Name)
+	if err := storage.AddPartial(partial); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar el partial en el almacenamiento persistente.", "details": err.Error()})
+	}
+
+	status := fiber.StatusCreated
+	if existed {
+		status = fiber.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{"message": "Partial guardado exitosamente", "name": partial.Name})
+}
+
+// GetPartials maneja la solicitud GET /configure-mock/partials
+func GetPartials(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(storage.GetAllPartials())
+}
+
+// GetPartial maneja la solicitud GET /configure-mock/partials/:name
+func GetPartial(c *fiber.Ctx) error {
+	partial, ok := storage.GetPartial(c.Params("name"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Partial no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(partial)
+}
+
+// DeletePartial maneja la solicitud DELETE /configure-mock/partials/:name
+func DeletePartial(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El nombre del partial es requerido"})
+	}
+
+	if deleted := storage.DeletePartial(name); !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Partial no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Partial eliminado exitosamente"})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"strings"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetProxyConfig maneja la solicitud GET /configure-mock/proxy
+func GetProxyConfig(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(storage.GetProxyConfig())
+}
+
+// SetProxyConfig maneja la solicitud PUT /configure-mock/proxy.
+// Un objeto vacío desactiva el proxy.
+func SetProxyConfig(c *fiber.Ctx) error {
+	var config models.ProxyConfig
+	if err := c.BodyParser(&config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear la configuración del proxy", "details": err.Error()})
+	}
+
+	// Normalización y validación del upstream global
+	config.DefaultUpstream = strings.TrimSpace(config.DefaultUpstream)
+	if config.DefaultUpstream != "" {
+		if err := validateUpstreamURL(config.DefaultUpstream); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'defaultUpstream' es inválido.", "details": err.Error()})
+		}
+	}
+
+	// Validación de las rutas por prefijo
+	seen := make(map[string]bool, len(config.Routes))
+	for i := range config.Routes {
+		route := &config.Routes[i]
+		route.PathPrefix = strings.TrimSpace(route.PathPrefix)
+		route.Upstream = strings.TrimSpace(route.Upstream)
+		if !strings.HasPrefix(route.PathPrefix, "/") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'pathPrefix' de cada ruta es requerido y debe iniciar con '/'.", "index": i})
+		}
+		if seen[route.PathPrefix] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Hay rutas de proxy con el mismo 'pathPrefix'.", "pathPrefix": route.PathPrefix})
+		}
+		seen[route.PathPrefix] = true
+		if err := validateUpstreamURL(route.Upstream); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'upstream' de la ruta es inválido.", "index": i, "details": err.Error()})
+		}
+	}
+
+	if err := storage.SaveProxyConfig(config); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la configuración del proxy en el almacenamiento persistente.", "details": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(config)
+}
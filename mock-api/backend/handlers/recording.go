@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// recordedHeaderExclusions son los headers de respuesta que no se copian a los mocks grabados
+// (además de los hop-by-hop), porque el servidor los genera en cada respuesta.
+// El body grabado ya está descomprimido, por lo que tampoco se copian Content-Encoding ni Content-Length.
+var recordedHeaderExclusions = map[string]bool{
+	"content-type":     true,
+	"content-encoding": true,
+	"content-length":   true,
+	"date":             true,
+	"server":           true,
+}
+
+// serveRecording reenvía la solicitud al destino de la grabación en curso y guarda el par
+// solicitud/respuesta. Devuelve false si no hay una grabación activa.
+func serveRecording(c *fiber.Ctx, req requestData) (bool, error) {
+	config, active := storage.ActiveRecording()
+	if !active {
+		return false, nil
+	}
+	if isProxyLoop(req) {
+		return true, c.Status(fiber.StatusLoopDetected).JSON(fiber.Map{"error": "Se detectó un ciclo de proxy: la solicitud ya fue reenviada por este servidor.", "upstream": config.Target})
+	}
+
+	resp, err := forwardRequest(c, req, config.Target, req.Path)
+	if err != nil {
+		log.Printf("Error al grabar %s %s desde '%s': %v", req.Method, req.Path, config.Target, err)
+		return true, c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "No se pudo contactar el servicio upstream.", "upstream": config.Target, "details": err.Error()})
+	}
+
+	responseHeaders := make(map[string]string, len(resp.Headers))
+	for name := range resp.Headers {
+		responseHeaders[name] = resp.Headers.Get(name)
+	}
+	exchange := models.RecordedExchange{
+		Method:          req.Method,
+		Path:            req.Path,
+		QueryParams:     req.Query,
+		Headers:         req.Headers,
+		Body:            req.Body,
+		Status:          resp.Status,
+		ResponseHeaders: responseHeaders,
+		ResponseBody:    string(resp.Body),
+		RecordedAt:      time.Now().UTC(),
+	}
+	// Un body binario (imágenes, PDF, etc.) no sobrevive como string en JSON: se guarda en base64
+	if !utf8.Valid(resp.Body) {
+		exchange.ResponseBody = base64.StdEncoding.EncodeToString(resp.Body)
+		exchange.ResponseBodyBase64 = true
+	}
+	saved := storage.AddRecordedExchange(exchange)
+	if saved {
+		log.Printf("Grabado %s %s desde '%s' -> %d", req.Method, req.Path, config.Target, resp.Status)
+	} else {
+		log.Printf("Límite de la grabación alcanzado: %s %s se reenvió pero no se grabó", req.Method, req.Path)
+	}
+	return true, sendProxiedResponse(c, resp)
+}
+
+// recordedContentType adapta el Content-Type de una respuesta grabada a los tipos soportados por los mocks.
+func recordedContentType(contentType string) string {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "json"):
+		return "application/json"
+	case strings.Contains(contentType, "xml"):
+		return "application/xml"
+	case strings.HasPrefix(contentType, "text/html"):
+		return "text/html"
+	case strings.HasPrefix(contentType, "text/"), contentType == "":
+		return "text/plain"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// recordingToMock convierte un par grabado en un mock, usando como criterios de coincidencia
+// las partes de la solicitud indicadas en la configuración de la grabación.
+func recordingToMock(config models.RecordingConfig, exchange models.RecordedExchange) models.MockConfig {
+	mock := models.MockConfig{
+		Id:                 uuid.New().String(),
+		Path:               exchange.Path,
+		Method:             exchange.Method,
+		QueryParams:        make(map[string]string),
+		BodyParams:         make(map[string]interface{}),
+		Headers:            make(map[string]string),
+		ResponseStatusCode: exchange.Status,
+		ResponseHeaders:    make(map[string]string),
+	}
+
+	if config.MatchQueryParams {
+		for name, value := range exchange.QueryParams {
+			mock.QueryParams[name] = value
+		}
+	}
+	for _, name := range config.MatchHeaders {
+		if value, ok := exchange.Headers[name]; ok {
+			mock.Headers[name] = value
+		}
+	}
+	// Solo los valores simples del body pueden compararse como criterio de coincidencia
+	if config.MatchBody {
+		for name, value := range exchange.Body {
+			switch value.(type) {
+			case string, float64, bool, nil:
+				mock.BodyParams[name] = value
+			}
+		}
+	}
+
+	for name, value := range exchange.ResponseHeaders {
+		lower := strings.ToLower(name)
+		if !hopByHopHeaders[lower] && !recordedHeaderExclusions[lower] {
+			mock.ResponseHeaders[name] = value
+		}
+	}
+
+	mock.ContentType = recordedContentType(exchange.ResponseHeaders["Content-Type"])
+	mock.ResponseBody = exchange.ResponseBody
+	if exchange.ResponseBodyBase64 {
+		// Un body que no es texto tampoco puede ser JSON; el mock lo decodifica al responder
+		mock.Base64Body = true
+		if mock.ContentType == "application/json" {
+			mock.ContentType = "application/octet-stream"
+		}
+	} else if mock.ContentType == "application/json" {
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(exchange.ResponseBody), &parsed); err == nil {
+			mock.ResponseBody = parsed
+		} else {
+			mock.ContentType = "text/plain"
+		}
+	}
+	// Los bodies que no son JSON se responden tal cual, igual que los devolvió el upstream
+	if mock.ContentType != "application/json" {
+		mock.RawBody = true
+	}
+	return mock
+}
+
+// recordingMockKey identifica los mocks grabados equivalentes (mismo método, ruta y criterios).
+func recordingMockKey(mock models.MockConfig) string {
+	matchers, _ := json.Marshal([]interface{}{mock.QueryParams, mock.Headers, mock.BodyParams})
+	return mock.Method + " " + mock.Path + " " + string(matchers)
+}
+
+// StartRecording maneja la solicitud POST /configure-mock/recordings/start
+func StartRecording(c *fiber.Ctx) error {
+	var config models.RecordingConfig
+	if err := c.BodyParser(&config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear la configuración de la grabación", "details": err.Error()})
+	}
+
+	config.Target = strings.TrimSpace(config.Target)
+	if err := validateUpstreamURL(config.Target); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'target' es inválido.", "details": err.Error()})
+	}
+	headers := make([]string, 0, len(config.MatchHeaders))
+	for _, name := range config.MatchHeaders {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			headers = append(headers, name)
+		}
+	}
+	config.MatchHeaders = headers
+
+	if err := storage.StartRecording(config); err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Ya hay una grabación en curso. Deténgala antes de iniciar otra."})
+	}
+	log.Printf("Grabación iniciada hacia '%s'", config.Target)
+	return c.Status(fiber.StatusOK).JSON(storage.GetRecordingStatus())
+}
+
+// GetRecordingStatus maneja la solicitud GET /configure-mock/recordings/status
+func GetRecordingStatus(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(storage.GetRecordingStatus())
+}
+
+// StopRecording maneja la solicitud POST /configure-mock/recordings/stop.
+// Convierte los pares grabados en mocks (sin duplicados) y los guarda, salvo con '?dryRun=true'. Cada mock
+// se valida y se crea igual que en ConfigureMock; los que fallan se informan en 'results' sin impedir
+// que se guarden los demás.
+func StopRecording(c *fiber.Ctx) error {
+	config, exchanges, dropped, ok := storage.StopRecording()
+	if !ok {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "No hay una grabación en curso."})
+	}
+
+	// Las solicitudes repetidas conservan la primera respuesta grabada
+	mocks := make([]models.MockConfig, 0, len(exchanges))
+	seen := make(map[string]bool, len(exchanges))
+	for _, exchange := range exchanges {
+		mock := recordingToMock(config, exchange)
+		key := recordingMockKey(mock)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mocks = append(mocks, mock)
+	}
+
+	dryRun := c.QueryBool("dryRun")
+	workspace, author := workspaceOf(c), changeAuthor(c)
+	saved := make([]models.MockConfig, 0, len(mocks))
+	results := make([]models.MockImportResult, 0, len(mocks))
+	for i, mock := range mocks {
+		result := models.MockImportResult{Index: i, Id: mock.Id, Action: models.HistoryActionCreate}
+		if errBody := validateMockConfig(&mock); errBody != nil {
+			result.Action = models.ImportActionInvalid
+			result.Error, _ = errBody["error"].(string)
+			result.Details = errBody["details"]
+			results = append(results, result)
+			continue
+		}
+		if !dryRun {
+			created, err := storage.CreateMockConfig(workspace, mock, author)
+			if err != nil {
+				result.Action = models.ImportActionInvalid
+				result.Error = "No se pudo guardar la configuración del mock en el almacenamiento persistente."
+				if errors.Is(err, storage.ErrMockExists) {
+					result.Error = "Ya existe un mock con el mismo ID."
+				}
+				result.Details = err.Error()
+				results = append(results, result)
+				continue
+			}
+			mock = created
+			result.Revision = created.Revision
+		}
+		saved = append(saved, mock)
+		results = append(results, result)
+	}
+	log.Printf("Grabación detenida: %d solicitudes, %d mocks generados, %d con errores", len(exchanges), len(saved), len(mocks)-len(saved))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Grabación detenida exitosamente",
+		"recorded":   len(exchanges),
+		"dropped":    dropped,
+		"duplicates": len(exchanges) - len(mocks),
+		"saved":      !dryRun,
+		"failed":     len(mocks) - len(saved),
+		"mocks":      saved,
+		"results":    results,
+	})
+}
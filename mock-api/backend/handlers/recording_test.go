@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRecordingBinaryBody(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	storage.InitMockStorage()
+
+	// Cabecera de un PNG: incluye bytes que no son UTF-8 válido
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff, 0xfe}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/logo.png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(png)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("señal"))
+	}))
+	defer upstream.Close()
+
+	if err := storage.StartRecording(models.RecordingConfig{Target: upstream.URL}); err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Post("/configure-mock/recordings/stop", StopRecording)
+	app.All("/*", ExecuteMock)
+
+	get := func(path string) []byte {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return body
+	}
+
+	// Grabación: la respuesta del upstream llega intacta
+	if got := get("/logo.png"); !bytes.Equal(got, png) {
+		t.Errorf("respuesta grabada = %v, se esperaba %v", got, png)
+	}
+	get("/saludo.txt")
+	resp, err := app.Test(httptest.NewRequest("POST", "/configure-mock/recordings/stop", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("detener la grabación: %v %v", resp, err)
+	}
+
+	// Los mocks generados devuelven los mismos bytes, sin el upstream y después de recargarlos del archivo
+	upstream.Close()
+	storage.InitMockStorage()
+	if got := get("/logo.png"); !bytes.Equal(got, png) {
+		t.Errorf("respuesta del mock = %v, se esperaba %v", got, png)
+	}
+	if got := string(get("/saludo.txt")); got != "señal" {
+		t.Errorf("respuesta del mock de texto = %q, se esperaba %q", got, "señal")
+	}
+}
+
+func TestValidateMockConfigBase64Body(t *testing.T) {
+	tests := []struct {
+		config  models.MockConfig
+		wantErr bool
+	}{
+		{models.MockConfig{RawBody: true, Base64Body: true, ResponseBody: "iVBORw=="}, false},
+		{models.MockConfig{RawBody: true, Base64Body: true, ResponseBody: "no es base64"}, true},
+		{models.MockConfig{Base64Body: true, ResponseBody: "iVBORw=="}, true},
+	}
+	for _, tt := range tests {
+		config := tt.config
+		config.Path, config.Method, config.ResponseStatusCode, config.ContentType = "/logo.png", "GET", 200, "application/octet-stream"
+		if errBody := validateMockConfig(&config); (errBody != nil) != tt.wantErr {
+			t.Errorf("%+v: se obtuvo %v, ¿error esperado? %v", tt.config, errBody, tt.wantErr)
+		}
+	}
+}
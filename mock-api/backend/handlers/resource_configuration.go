@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// resourcePathRegex valida la ruta base de un recurso (sin parámetros de ruta).
+var resourcePathRegex = regexp.MustCompile(`^(/[\w.-]+)+$`)
+
+// ConfigureResource maneja la solicitud POST /configure-mock/resources
+func ConfigureResource(c *fiber.Ctx) error {
+	var resource models.ResourceConfig
+
+	// Parsear el cuerpo de la solicitud a la estructura ResourceConfig
+	if err := c.BodyParser(&resource); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear la configuración del recurso", "details": err.Error()})
+	}
+
+	// Normalización de campos
+	resource.Path = strings.TrimSuffix(strings.TrimSpace(resource.Path), "/")
+	resource.IdField = strings.TrimSpace(resource.IdField)
+	if resource.IdField == "" {
+		resource.IdField = "id"
+	}
+	resource.Name = strings.TrimSpace(resource.Name)
+	if resource.Name == "" && resource.Path != "" {
+		// Por defecto el nombre es el último segmento de la ruta: /api/v1/productos -> productos
+		resource.Name = resource.Path[strings.LastIndex(resource.Path, "/")+1:]
+	}
+
+	// VALIDACIONES
+	if resource.Path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'path' es requerido y no puede estar vacío."})
+	}
+	if !resourcePathRegex.MatchString(resource.Path) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'path' tiene un formato URL inválido. Ejemplo válido: /api/v1/productos."})
+	}
+	if !partialNameRegex.MatchString(resource.Name) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'name' es inválido. Debe iniciar con una letra y contener solo letras, números, '.', '-' o '_'."})
+	}
+	for _, existing := range storage.GetAllResourceConfigs() {
+		if existing.Name != resource.Name && existing.Path == resource.Path {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Ya existe otro recurso con la misma ruta.", "resource": existing.Name})
+		}
+		if existing.Name == resource.Name && existing.Path != resource.Path {
+			// Reemplazarlo en silencio borraría los datos de otro recurso; para cambiar la ruta hay que eliminarlo antes
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Ya existe otro recurso con el mismo nombre y otra ruta. Indique un 'name' distinto o elimine el recurso existente.", "resource": existing.Name, "path": existing.Path})
+		}
+	}
+
+	// Los IDs de los datos semilla deben ser únicos
+	seen := make(map[string]bool, len(resource.Seed))
+	for i, item := range resource.Seed {
+		if item == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cada elemento de 'seed' debe ser un objeto JSON.", "index": i})
+		}
+		id := toString(item[resource.IdField])
+		if id == "" {
+			continue
+		}
+		if seen[id] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Los datos de 'seed' contienen IDs duplicados.", "id": id})
+		}
+		seen[id] = true
+	}
+
+	_, existed := storage.GetResourceConfig(resource.Name)
+	if err := storage.SaveResourceConfig(resource); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar el recurso en el almacenamiento persistente.", "details": err.Error()})
+	}
+
+	status := fiber.StatusCreated
+	if existed {
+		status = fiber.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{"message": "Recurso guardado exitosamente", "name": resource.Name, "path": resource.Path})
+}
+
+// GetResources maneja la solicitud GET /configure-mock/resources
+func GetResources(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(storage.GetAllResourceConfigs())
+}
+
+// GetResource maneja la solicitud GET /configure-mock/resources/:name
+func GetResource(c *fiber.Ctx) error {
+	resource, ok := storage.GetResourceConfig(c.Params("name"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Recurso no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(resource)
+}
+
+// ResetResource maneja la solicitud POST /configure-mock/resources/:name/reset
+func ResetResource(c *fiber.Ctx) error {
+	if !storage.ResetResourceData(strings.Clone(c.Params("name"))) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Recurso no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Datos del recurso reiniciados exitosamente"})
+}
+
+// DeleteResource maneja la solicitud DELETE /configure-mock/resources/:name
+func DeleteResource(c *fiber.Ctx) error {
+	if !storage.DeleteResourceConfig(c.Params("name")) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Recurso no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Recurso eliminado exitosamente"})
+}
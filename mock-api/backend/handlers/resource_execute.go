@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Parámetros de consulta reservados para listar recursos; el resto se usa como filtro por campo.
+var reservedResourceParams = map[string]bool{
+	"_sort":  true,
+	"_order": true,
+	"_page":  true,
+	"_limit": true,
+	"q":      true,
+}
+
+// findResource busca el recurso que atiende la ruta: la colección ('/productos') o un elemento ('/productos/7').
+func findResource(path string) (models.ResourceConfig, string, bool) {
+	path = strings.TrimSuffix(path, "/")
+	for _, resource := range storage.GetAllResourceConfigs() {
+		if path == resource.Path {
+			return resource, "", true
+		}
+		if rest, ok := strings.CutPrefix(path, resource.Path+"/"); ok && rest != "" && !strings.Contains(rest, "/") {
+			return resource, rest, true
+		}
+	}
+	return models.ResourceConfig{}, "", false
+}
+
+// serveResource atiende una solicitud CRUD sobre un recurso declarado. Devuelve false si
+// ningún recurso corresponde a la ruta, para que ExecuteMock continúe con su respuesta por defecto.
+func serveResource(c *fiber.Ctx, req requestData) (bool, error) {
+	resource, id, ok := findResource(req.Path)
+	if !ok {
+		return false, nil
+	}
+
+	if id == "" {
+		switch req.Method {
+		case fiber.MethodGet, fiber.MethodHead:
+			return true, listResourceItems(c, resource, req)
+		case fiber.MethodPost:
+			return true, createResourceItem(c, resource, req)
+		}
+	} else {
+		switch req.Method {
+		case fiber.MethodGet, fiber.MethodHead:
+			item, err := storage.GetResourceItem(resource.Name, id)
+			if err != nil {
+				return true, resourceError(c, err)
+			}
+			return true, c.Status(fiber.StatusOK).JSON(item)
+		case fiber.MethodPut:
+			return true, replaceResourceItem(c, resource, id, req)
+		case fiber.MethodPatch:
+			return true, patchResourceItem(c, resource, id, req)
+		case fiber.MethodDelete:
+			if err := storage.DeleteResourceItem(resource.Name, id); err != nil {
+				return true, resourceError(c, err)
+			}
+			return true, c.SendStatus(fiber.StatusNoContent)
+		}
+	}
+
+	return true, c.Status(fiber.StatusMethodNotAllowed).JSON(fiber.Map{"error": "Método no permitido para el recurso", "resource": resource.Name, "method": req.Method})
+}
+
+// resourceError traduce los errores del almacenamiento a respuestas HTTP.
+func resourceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, storage.ErrItemNotFound), errors.Is(err, storage.ErrResourceNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Elemento no encontrado"})
+	case errors.Is(err, storage.ErrItemConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Ya existe un elemento con el mismo ID"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error al procesar el recurso", "details": err.Error()})
+}
+
+// parseResourceBody interpreta el body de la solicitud como un objeto JSON.
+func parseResourceBody(req requestData) (map[string]interface{}, error) {
+	var item map[string]interface{}
+	if err := json.Unmarshal([]byte(req.RawBody), &item); err != nil || item == nil {
+		return nil, errors.New("el body debe ser un objeto JSON")
+	}
+	return item, nil
+}
+
+// listResourceItems lista los elementos aplicando filtros por campo, búsqueda 'q',
+// ordenamiento ('_sort', '_order') y paginación ('_page', '_limit'). El total se devuelve en 'X-Total-Count'.
+func listResourceItems(c *fiber.Ctx, resource models.ResourceConfig, req requestData) error {
+	items, err := storage.ListResourceItems(resource.Name)
+	if err != nil {
+		return resourceError(c, err)
+	}
+
+	// Filtros por campo (igualdad como texto; varios valores del mismo campo se combinan con OR)
+	filtered := items[:0]
+	for _, item := range items {
+		if matchResourceFilters(item, req) {
+			filtered = append(filtered, item)
+		}
+	}
+	items = filtered
+
+	// Ordenamiento por uno o varios campos separados por comas
+	if sortFields := req.Query["_sort"]; sortFields != "" {
+		fields := strings.Split(sortFields, ",")
+		orders := strings.Split(req.Query["_order"], ",")
+		sort.SliceStable(items, func(i, j int) bool {
+			for k, field := range fields {
+				cmp := compareValues(items[i][field], items[j][field])
+				if cmp == 0 {
+					continue
+				}
+				if k < len(orders) && strings.EqualFold(orders[k], "desc") {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+
+	// Paginación
+	total := len(items)
+	c.Set("X-Total-Count", strconv.Itoa(total))
+	if limitStr, ok := req.Query["_limit"]; ok {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El parámetro '_limit' debe ser un entero no negativo."})
+		}
+		page := 1
+		if pageStr, ok := req.Query["_page"]; ok {
+			page, err = strconv.Atoi(pageStr)
+			if err != nil || page < 1 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El parámetro '_page' debe ser un entero mayor que 0."})
+			}
+		}
+		start := min((page-1)*limit, total)
+		end := min(start+limit, total)
+		items = items[start:end]
+	}
+
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// matchResourceFilters verifica los filtros por campo y la búsqueda de texto 'q'.
+func matchResourceFilters(item map[string]interface{}, req requestData) bool {
+	for field, values := range req.QueryAll {
+		if reservedResourceParams[field] {
+			continue
+		}
+		actual := toString(item[field])
+		found := false
+		for _, v := range values {
+			if actual == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if q := strings.ToLower(req.Query["q"]); q != "" {
+		data, _ := json.Marshal(item)
+		return strings.Contains(strings.ToLower(string(data)), q)
+	}
+	return true
+}
+
+// compareValues compara dos valores numéricamente si ambos son números, o como texto en caso contrario.
+func compareValues(a, b interface{}) int {
+	fa, errA := toFloat(a)
+	fb, errB := toFloat(b)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(toString(a), toString(b))
+}
+
+// createResourceItem crea un elemento y responde 201 con el header 'Location'.
+func createResourceItem(c *fiber.Ctx, resource models.ResourceConfig, req requestData) error {
+	item, err := parseResourceBody(req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	created, err := storage.CreateResourceItem(resource.Name, item)
+	if err != nil {
+		return resourceError(c, err)
+	}
+	c.Set(fiber.HeaderLocation, resource.Path+"/"+toString(created[resource.IdField]))
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// changesResourceID indica si el body de un PUT o PATCH cambia el ID del elemento. Repetir el mismo ID está
+// permitido; un valor distinto, o null en un PATCH (que eliminaría el campo), no.
+func changesResourceID(resource models.ResourceConfig, id string, body map[string]interface{}) bool {
+	bodyID, ok := body[resource.IdField]
+	return ok && (bodyID == nil || toString(bodyID) != id)
+}
+
+// resourceIDMismatch responde 400 cuando el body intenta cambiar el ID del elemento.
+func resourceIDMismatch(c *fiber.Ctx, resource models.ResourceConfig) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo '" + resource.IdField + "' del body no coincide con el ID de la ruta."})
+}
+
+// replaceResourceItem reemplaza un elemento completo (PUT).
+func replaceResourceItem(c *fiber.Ctx, resource models.ResourceConfig, id string, req requestData) error {
+	item, err := parseResourceBody(req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if changesResourceID(resource, id, item) {
+		return resourceIDMismatch(c, resource)
+	}
+	replaced, err := storage.ReplaceResourceItem(resource.Name, id, item)
+	if err != nil {
+		return resourceError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(replaced)
+}
+
+// patchResourceItem actualiza parcialmente un elemento con JSON Merge Patch (PATCH).
+func patchResourceItem(c *fiber.Ctx, resource models.ResourceConfig, id string, req requestData) error {
+	patch, err := parseResourceBody(req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if changesResourceID(resource, id, patch) {
+		return resourceIDMismatch(c, resource)
+	}
+	updated, err := storage.UpdateResourceItem(resource.Name, id, func(item map[string]interface{}) map[string]interface{} {
+		return applyMergePatch(item, patch).(map[string]interface{})
+	})
+	if err != nil {
+		return resourceError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(updated)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResourceItemIDChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	resource := models.ResourceConfig{Name: "productos", Path: "/productos", IdField: "id", Seed: []map[string]interface{}{{"id": "1", "name": "mesa"}}}
+	if err := storage.SaveResourceConfig(resource); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.DeleteResourceConfig(resource.Name) })
+
+	app := fiber.New()
+	app.All("/*", func(c *fiber.Ctx) error {
+		_, err := serveResource(c, extractRequestData(c))
+		return err
+	})
+
+	// PUT y PATCH aceptan el mismo ID en el body y rechazan uno distinto
+	tests := []struct {
+		method, body string
+		wantStatus   int
+	}{
+		{"PUT", `{"id":"2","name":"silla"}`, fiber.StatusBadRequest},
+		{"PATCH", `{"id":"2"}`, fiber.StatusBadRequest},
+		{"PATCH", `{"id":2}`, fiber.StatusBadRequest},
+		{"PATCH", `{"id":null}`, fiber.StatusBadRequest},
+		{"PATCH", `{"id":"1","name":"banco"}`, fiber.StatusOK},
+		{"PATCH", `{"name":"taburete"}`, fiber.StatusOK},
+		{"PUT", `{"id":"1","name":"silla"}`, fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/productos/1", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s %s: estado %d (%s), se esperaba %d", tt.method, tt.body, resp.StatusCode, body, tt.wantStatus)
+		}
+	}
+
+	if item, err := storage.GetResourceItem(resource.Name, "1"); err != nil || item["name"] != "silla" {
+		t.Errorf("elemento final %v (%v)", item, err)
+	}
+	if _, err := storage.GetResourceItem(resource.Name, "2"); err == nil {
+		t.Error("no debería existir un elemento con ID 2")
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"strings"
+
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetScenarios maneja la solicitud GET /configure-mock/scenarios
+func GetScenarios(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(storage.GetAllScenarios(workspaceOf(c)))
+}
+
+// GetScenario maneja la solicitud GET /configure-mock/scenarios/:name
+func GetScenario(c *fiber.Ctx) error {
+	name := strings.Clone(c.Params("name"))
+	for _, scenario := range storage.GetAllScenarios(workspaceOf(c)) {
+		if scenario.Name == name {
+			return c.Status(fiber.StatusOK).JSON(scenario)
+		}
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Escenario no encontrado"})
+}
+
+// SetScenarioState maneja la solicitud PUT /configure-mock/scenarios/:name/state
+func SetScenarioState(c *fiber.Ctx) error {
+	var body struct {
+		State string `json:"state"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear el estado del escenario", "details": err.Error()})
+	}
+	body.State = strings.TrimSpace(body.State)
+	if body.State == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El campo 'state' es requerido y no puede estar vacío."})
+	}
+
+	name := strings.Clone(c.Params("name"))
+	storage.SetScenarioState(workspaceOf(c), name, body.State)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Estado del escenario actualizado exitosamente", "name": name, "state": body.State})
+}
+
+// ResetScenario maneja la solicitud POST /configure-mock/scenarios/:name/reset
+func ResetScenario(c *fiber.Ctx) error {
+	name := strings.Clone(c.Params("name"))
+	storage.ResetScenario(workspaceOf(c), name)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Escenario reiniciado exitosamente", "name": name, "state": storage.GetScenarioState(workspaceOf(c), name)})
+}
+
+// ResetAllScenarios maneja la solicitud POST /configure-mock/scenarios/reset
+func ResetAllScenarios(c *fiber.Ctx) error {
+	storage.ResetAllScenarios(workspaceOf(c))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Todos los escenarios fueron reiniciados exitosamente"})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetStore maneja la solicitud GET /configure-mock/store
+func GetStore(c *fiber.Ctx) error {
+	all := fiber.Map{}
+	for _, namespace := range storage.GetStoreNamespaces() {
+		if values, ok := storage.GetStoreNamespace(namespace); ok {
+			all[namespace] = values
+		}
+	}
+	return c.Status(fiber.StatusOK).JSON(all)
+}
+
+// ClearStore maneja la solicitud DELETE /configure-mock/store
+func ClearStore(c *fiber.Ctx) error {
+	storage.ClearStore()
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Store vaciado exitosamente"})
+}
+
+// GetStoreNamespace maneja la solicitud GET /configure-mock/store/:namespace
+func GetStoreNamespace(c *fiber.Ctx) error {
+	values, ok := storage.GetStoreNamespace(c.Params("namespace"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Namespace no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(values)
+}
+
+// SeedStoreNamespace maneja la solicitud POST /configure-mock/store/:namespace.
+// El body es un objeto JSON cuyas claves se agregan (o reemplazan) en el namespace.
+func SeedStoreNamespace(c *fiber.Ctx) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal(c.Body(), &values); err != nil || values == nil {
+		details := "se esperaba un objeto JSON"
+		if err != nil {
+			details = err.Error()
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudieron parsear los datos del store", "details": details})
+	}
+
+	namespace := strings.Clone(c.Params("namespace"))
+	if err := storage.SeedStoreNamespace(namespace, values); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudieron guardar los datos del store.", "details": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Datos del store guardados exitosamente", "namespace": namespace, "keys": len(values)})
+}
+
+// ClearStoreNamespace maneja la solicitud DELETE /configure-mock/store/:namespace
+func ClearStoreNamespace(c *fiber.Ctx) error {
+	if !storage.ClearStoreNamespace(c.Params("namespace")) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Namespace no encontrado"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Namespace vaciado exitosamente"})
+}
+
+// GetStoreValue maneja la solicitud GET /configure-mock/store/:namespace/:key
+func GetStoreValue(c *fiber.Ctx) error {
+	value, ok := storage.GetStoreValue(c.Params("namespace"), c.Params("key"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Clave no encontrada"})
+	}
+	return c.Status(fiber.StatusOK).JSON(value)
+}
+
+// SetStoreValue maneja la solicitud PUT /configure-mock/store/:namespace/:key.
+// El body es el valor JSON (de cualquier tipo) que se guarda en la clave.
+func SetStoreValue(c *fiber.Ctx) error {
+	var value interface{}
+	if err := json.Unmarshal(c.Body(), &value); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No se pudo parsear el valor de la clave", "details": err.Error()})
+	}
+
+	namespace, key := strings.Clone(c.Params("namespace")), strings.Clone(c.Params("key"))
+	if err := storage.SetStoreValue(namespace, key, value); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "No se pudo guardar la clave en el store.", "details": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Clave guardada exitosamente", "namespace": namespace, "key": key})
+}
+
+// DeleteStoreValue maneja la solicitud DELETE /configure-mock/store/:namespace/:key
+func DeleteStoreValue(c *fiber.Ctx) error {
+	if !storage.DeleteStoreValue(c.Params("namespace"), c.Params("key")) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Clave no encontrada"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Clave eliminada exitosamente"})
+}
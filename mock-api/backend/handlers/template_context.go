@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestData agrupa la información extraída de la solicitud entrante.
+// Se copia a tipos propios de Go porque los buffers de Fiber se reutilizan al terminar el handler.
+type requestData struct {
+	Path     string
+	Method   string
+	Query    map[string]string
+	QueryAll map[string][]string
+	Headers  map[string]string // Claves en minúsculas
+	Cookies  map[string]string
+	Body     models.RequestBody
+	RawBody  string
+	ClientIP string
+}
+
+// extractRequestData lee de la solicitud de Fiber todos los datos necesarios para el matching y las plantillas.
+func extractRequestData(c *fiber.Ctx) requestData {
+	req := requestData{
+		Path:     strings.Clone(c.Path()),
+		Method:   strings.Clone(c.Method()),
+		Query:    make(map[string]string),
+		QueryAll: make(map[string][]string),
+		Headers:  make(map[string]string),
+		Cookies:  make(map[string]string),
+		RawBody:  string(c.Body()),
+		ClientIP: strings.Clone(c.IP()),
+	}
+
+	// Query params: el primer valor en Query y todos los valores en QueryAll
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		k := string(key)
+		if _, exists := req.Query[k]; !exists {
+			req.Query[k] = string(value)
+		}
+		req.QueryAll[k] = append(req.QueryAll[k], string(value))
+	})
+
+	// Headers normalizados a minúsculas
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		req.Headers[strings.ToLower(string(key))] = string(value)
+	})
+
+	// Cookies
+	c.Request().Header.VisitAllCookie(func(key, value []byte) {
+		req.Cookies[string(key)] = string(value)
+	})
+
+	// Body JSON (si aplica)
+	if len(req.RawBody) > 0 && isJSONContentType(c.Get("Content-Type")) {
+		if err := json.Unmarshal([]byte(req.RawBody), &req.Body); err != nil {
+			log.Printf("Advertencia: No se pudo parsear el cuerpo JSON de la solicitud para %s %s: %v", req.Method, req.Path, err)
+			// Continuar sin el body parseado si hay error que puede ser un JSON mal formado
+		}
+	}
+
+	return req
+}
+
+// isJSONContentType indica si el body de una solicitud con ese Content-Type se parsea como JSON.
+func isJSONContentType(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "application/json")
+}
+
+// buildTemplateContext construye el contexto de datos (versión models.CurrentTemplateVersion) disponible en las plantillas:
+//
+//	.Version                     versión del contexto
+//	.Request.Path / .Method      ruta y método de la solicitud
+//	.Request.PathParams.<name>   parámetros de ruta definidos como ':name' en el path del mock
+//	.Request.Query.<name>        primer valor de cada query param
+//	.Request.QueryAll.<name>     todos los valores de cada query param
+//	.Request.Headers.<name>      headers con nombre en minúsculas
+//	.Request.Cookies.<name>      cookies
+//	.Request.Body                body JSON parseado
+//	.Request.RawBody             body sin procesar
+//	.Request.ClientIP            IP del cliente
+//	.Mock.Id / .Path / .Method / .Priority   metadatos del mock que respondió
+//	.Server.Time / .Server.Timestamp         hora del servidor (UTC) y segundos Unix
+func buildTemplateContext(req requestData, pathParams map[string]string, config models.MockConfig) fiber.Map {
+	now := time.Now().UTC()
+	if pathParams == nil {
+		pathParams = make(map[string]string)
+	}
+
+	return fiber.Map{
+		"Version": models.CurrentTemplateVersion,
+		"Request": fiber.Map{
+			"Path":       req.Path,
+			"Method":     req.Method,
+			"PathParams": pathParams,
+			"Query":      req.Query,
+			"QueryAll":   req.QueryAll,
+			"Headers":    req.Headers,
+			"Cookies":    req.Cookies,
+			"Body":       req.Body, // Este será un map[string]interface{}
+			"RawBody":    req.RawBody,
+			"ClientIP":   req.ClientIP,
+		},
+		"Mock": fiber.Map{
+			"Id":       config.Id,
+			"Path":     config.Path,
+			"Method":   config.Method,
+			"Priority": config.Priority,
+		},
+		"Server": fiber.Map{
+			"Time":      now,
+			"Timestamp": now.Unix(),
+		},
+	}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"math/rand"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// fakerLocale contiene los datos de muestra de un idioma/región para generar datos falsos.
+type fakerLocale struct {
+	FirstNames   []string
+	LastNames    []string
+	Streets      []string
+	Cities       []string
+	States       []string
+	Country      string
+	TwoSurnames  bool // Los nombres completos llevan apellido paterno y materno
+	CompanyNames []string
+	CompanyTypes []string
+	EmailDomains []string
+	PhoneFormat  string // '#' se reemplaza por un dígito aleatorio
+	PostalFormat string
+	AddressFmt   string // %[1]s calle, %[2]d número, %[3]s ciudad, %[4]s estado, %[5]s código postal
+	IBANCountry  string
+	IBANBankLen  int // Cantidad de letras del código de banco
+	IBANAcctLen  int // Cantidad de dígitos de la cuenta
+	LoremWords   []string
+}
+
+// fakerLocales registra los idiomas soportados. "es" está orientado a Guatemala.
+var fakerLocales = map[string]fakerLocale{
+	"en": {
+		FirstNames:   []string{"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth", "David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen"},
+		LastNames:    []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Miller", "Davis", "Wilson", "Anderson", "Taylor", "Thomas", "Moore", "Martin", "Jackson", "Thompson", "White", "Harris", "Clark", "Lewis", "Walker"},
+		Streets:      []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Pine St", "Elm St", "Washington Blvd", "Lake Rd", "Hill St", "Park Ave"},
+		Cities:       []string{"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem", "Madison", "Georgetown"},
+		States:       []string{"CA", "TX", "NY", "FL", "IL", "PA", "OH", "GA", "NC", "MI"},
+		Country:      "United States",
+		CompanyNames: []string{"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Hooli", "Vandelay", "Soylent", "Cyberdyne"},
+		CompanyTypes: []string{"Inc.", "LLC", "Corp.", "Group", "Ltd."},
+		EmailDomains: []string{"example.com", "mail.test", "inbox.test"},
+		PhoneFormat:  "+1 (###) ###-####",
+		PostalFormat: "#####",
+		AddressFmt:   "%[2]d %[1]s, %[3]s, %[4]s %[5]s",
+		IBANCountry:  "GB",
+		IBANBankLen:  4,
+		IBANAcctLen:  14,
+		LoremWords:   loremWords,
+	},
+	"es": {
+		FirstNames:   []string{"José", "María", "Juan", "Ana", "Luis", "Carmen", "Carlos", "Rosa", "Jorge", "Lucía", "Miguel", "Sofía", "Pedro", "Gabriela", "Fernando", "Andrea", "Mario", "Daniela", "Enner", "Alejandra"},
+		LastNames:    []string{"García", "López", "Pérez", "González", "Rodríguez", "Hernández", "Morales", "Martínez", "Ramírez", "Castillo", "Mendizábal", "Chávez", "Ajú", "Xicará", "Barrios", "Orellana", "Monterroso", "Cifuentes", "Sical", "Tzul"},
+		Streets:      []string{"Avenida Reforma", "Calzada Roosevelt", "Avenida Las Américas", "Calle Real", "Boulevard Liberación", "Avenida Bolívar", "Calzada Aguilar Batres", "Diagonal 6", "Avenida Petapa", "Calle Montúfar"},
+		Cities:       []string{"Guatemala", "Mixco", "Villa Nueva", "Quetzaltenango", "Antigua Guatemala", "Escuintla", "Cobán", "Huehuetenango", "Chimaltenango", "Puerto Barrios"},
+		States:       []string{"Guatemala", "Sacatepéquez", "Quetzaltenango", "Escuintla", "Alta Verapaz", "Huehuetenango", "Chimaltenango", "Izabal", "Petén", "Sololá"},
+		Country:      "Guatemala",
+		TwoSurnames:  true,
+		CompanyNames: []string{"Cementos del Valle", "Distribuidora Maya", "Café Volcán", "Textiles Quetzal", "Agroexportadora Atitlán", "Servicios Chapines", "Transportes Pacaya", "Inversiones Tikal", "Comercial Xelajú", "Tecnología Ceiba"},
+		CompanyTypes: []string{"S.A.", "S.R.L.", "y Cía.", "Sociedad Anónima"},
+		EmailDomains: []string{"correo.gt", "ejemplo.com.gt", "mail.test"},
+		PhoneFormat:  "+502 ####-####",
+		PostalFormat: "01###",
+		AddressFmt:   "%[1]s %[2]d, %[3]s, %[4]s %[5]s",
+		IBANCountry:  "GT",
+		IBANBankLen:  4,
+		IBANAcctLen:  20,
+		LoremWords:   loremWords,
+	},
+}
+
+// loremWords es el vocabulario compartido para generar texto de relleno.
+var loremWords = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor
+incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris
+nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate velit esse cillum fugiat
+nulla pariatur excepteur sint occaecat cupidatat non proident sunt culpa qui officia deserunt mollit anim id est laborum`)
+
+// fakerSeedEpoch es la fecha de referencia de las fechas generadas después de 'fakeSeed'. Con una fecha fija
+// la misma semilla produce siempre las mismas fechas, sin importar el día en que se genere la respuesta.
+var fakerSeedEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// faker genera datos falsos para una única ejecución de plantilla.
+// Cada ejecución tiene su propio generador, de modo que 'fakeSeed' solo afecta a esa respuesta.
+type faker struct {
+	rng    *rand.Rand
+	locale fakerLocale
+	epoch  time.Time // Fecha desde la que se cuentan hacia atrás fakeDate y fakeBirthDate
+}
+
+// newFaker crea un generador con semilla aleatoria y el idioma "en". Las fechas se cuentan desde el día actual.
+func newFaker() *faker {
+	return &faker{
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		locale: fakerLocales["en"],
+		epoch:  time.Now().UTC().Truncate(24 * time.Hour),
+	}
+}
+
+// funcs devuelve las funciones de plantilla asociadas a este generador.
+func (f *faker) funcs() template.FuncMap {
+	return template.FuncMap{
+		"fakeSeed":      f.seed,
+		"fakeLocale":    f.setLocale,
+		"fakeFirstName": f.firstName,
+		"fakeLastName":  f.lastName,
+		"fakeName":      f.name,
+		"fakeEmail":     f.email,
+		"fakeUsername":  f.username,
+		"fakePhone":     f.phone,
+		"fakeStreet":    f.street,
+		"fakeCity":      f.city,
+		"fakeState":     f.state,
+		"fakeCountry":   func() string { return f.locale.Country },
+		"fakePostcode":  f.postcode,
+		"fakeAddress":   f.address,
+		"fakeCompany":   f.company,
+		"fakeLorem":     f.lorem,
+		"fakeSentence":  f.sentence,
+		"fakeParagraph": f.paragraph,
+		"fakeIBAN":      f.iban,
+		"fakeDate":      f.date,
+		"fakeBirthDate": f.birthDate,
+	}
+}
+
+// seed fija la semilla a partir de cualquier valor (por ejemplo un ID de la solicitud),
+// de modo que el mismo valor produzca siempre los mismos datos, incluidas las fechas, que pasan a
+// contarse desde fakerSeedEpoch. Devuelve una cadena vacía para poder usarse directamente en la
+// plantilla: '{{ fakeSeed .Request.Query.id }}'.
+func (f *faker) seed(v interface{}) string {
+	h := fnv.New64a()
+	h.Write([]byte(toString(v)))
+	f.rng.Seed(int64(h.Sum64()))
+	f.epoch = fakerSeedEpoch
+	return ""
+}
+
+// setLocale cambia el idioma de los datos generados ("en" o "es").
+func (f *faker) setLocale(code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i] // "es-GT" -> "es"
+	}
+	l, ok := fakerLocales[code]
+	if !ok {
+		return "", fmt.Errorf("fakeLocale: idioma no soportado %q", code)
+	}
+	f.locale = l
+	return "", nil
+}
+
+// pick elige un elemento aleatorio de la lista.
+func (f *faker) pick(list []string) string {
+	return list[f.rng.Intn(len(list))]
+}
+
+// digits reemplaza cada '#' del patrón por un dígito aleatorio.
+func (f *faker) digits(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		if r == '#' {
+			sb.WriteByte(byte('0' + f.rng.Intn(10)))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func (f *faker) firstName() string { return f.pick(f.locale.FirstNames) }
+func (f *faker) lastName() string  { return f.pick(f.locale.LastNames) }
+func (f *faker) street() string    { return f.pick(f.locale.Streets) }
+func (f *faker) city() string      { return f.pick(f.locale.Cities) }
+func (f *faker) state() string     { return f.pick(f.locale.States) }
+func (f *faker) postcode() string  { return f.digits(f.locale.PostalFormat) }
+func (f *faker) phone() string     { return f.digits(f.locale.PhoneFormat) }
+
+// name genera un nombre completo, con dos apellidos en los idiomas que lo acostumbran (TwoSurnames).
+func (f *faker) name() string {
+	if f.locale.TwoSurnames {
+		return f.firstName() + " " + f.lastName() + " " + f.lastName()
+	}
+	return f.firstName() + " " + f.lastName()
+}
+
+// username genera un nombre de usuario en minúsculas y sin acentos.
+func (f *faker) username() string {
+	return removeAccents(strings.ToLower(f.firstName()+"."+f.lastName())) + fmt.Sprint(f.rng.Intn(100))
+}
+
+// email genera un correo electrónico con un dominio reservado para pruebas.
+func (f *faker) email() string {
+	return f.username() + "@" + f.pick(f.locale.EmailDomains)
+}
+
+// address genera una dirección completa según el formato del idioma.
+func (f *faker) address() string {
+	return fmt.Sprintf(f.locale.AddressFmt, f.street(), 1+f.rng.Intn(9999), f.city(), f.state(), f.postcode())
+}
+
+// company genera un nombre de empresa.
+func (f *faker) company() string {
+	return f.pick(f.locale.CompanyNames) + " " + f.pick(f.locale.CompanyTypes)
+}
+
+// lorem genera n palabras de texto de relleno (5 por defecto).
+func (f *faker) lorem(n ...interface{}) (string, error) {
+	count := int64(5)
+	if len(n) > 0 {
+		c, err := toInt(n[0])
+		if err != nil {
+			return "", err
+		}
+		count = c
+	}
+	if count < 0 || count > 1000 {
+		return "", fmt.Errorf("fakeLorem: la cantidad de palabras debe estar entre 0 y 1000")
+	}
+	words := make([]string, count)
+	for i := range words {
+		words[i] = f.pick(f.locale.LoremWords)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// sentence genera una oración de 6 a 12 palabras.
+func (f *faker) sentence() string {
+	s, _ := f.lorem(6 + f.rng.Intn(7))
+	return capitalize(s) + "."
+}
+
+// paragraph genera un párrafo de 3 a 6 oraciones.
+func (f *faker) paragraph() string {
+	sentences := make([]string, 3+f.rng.Intn(4))
+	for i := range sentences {
+		sentences[i] = f.sentence()
+	}
+	return strings.Join(sentences, " ")
+}
+
+// iban genera un IBAN con dígitos de control válidos (GB para "en", GT para "es").
+func (f *faker) iban() string {
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	var bban strings.Builder
+	for i := 0; i < f.locale.IBANBankLen; i++ {
+		bban.WriteByte(letters[f.rng.Intn(len(letters))])
+	}
+	bban.WriteString(f.digits(strings.Repeat("#", f.locale.IBANAcctLen)))
+
+	country := f.locale.IBANCountry
+	return country + ibanCheckDigits(country, bban.String()) + bban.String()
+}
+
+// ibanCheckDigits calcula los dígitos de control ISO 7064 (mod 97-10) de un IBAN.
+func ibanCheckDigits(country, bban string) string {
+	var numeric strings.Builder
+	for _, r := range bban + country + "00" {
+		if r >= 'A' && r <= 'Z' {
+			numeric.WriteString(fmt.Sprint(int(r-'A') + 10))
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	n, _ := new(big.Int).SetString(numeric.String(), 10)
+	mod := new(big.Int).Mod(n, big.NewInt(97)).Int64()
+	return fmt.Sprintf("%02d", 98-mod)
+}
+
+// date genera una fecha aleatoria de los 10 años anteriores a la fecha de referencia. Se combina con
+// formatDate: '{{ fakeDate | formatDate "date" }}'.
+func (f *faker) date() time.Time {
+	days := f.rng.Intn(365 * 10)
+	return f.epoch.AddDate(0, 0, -days)
+}
+
+// birthDate genera una fecha de nacimiento de una persona entre 18 y 80 años a la fecha de referencia.
+func (f *faker) birthDate() time.Time {
+	days := 365*18 + f.rng.Intn(365*62)
+	return f.epoch.AddDate(0, 0, -days)
+}
+
+// removeAccents reemplaza las vocales acentuadas y la ñ para usar el texto en correos o usuarios.
+func removeAccents(s string) string {
+	return strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n").Replace(s)
+}
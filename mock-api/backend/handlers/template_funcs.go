@@ -0,0 +1,827 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"backend/storage"
+
+	"github.com/google/uuid"
+)
+
+// templateFuncMap construye la biblioteca de funciones disponible dentro de las plantillas de respuesta.
+// Las funciones que reciben un valor "principal" lo aceptan como último argumento para poder
+// usarse en pipelines, por ejemplo '{{ .Request.Query.name | default "anónimo" | upper }}'.
+func templateFuncMap() template.FuncMap {
+	defaultStore := templateStore{namespace: storage.DefaultStoreNamespace}
+	funcs := template.FuncMap{
+		// Serialización y acceso a mapas (funciones originales)
+		"json":        jsonMarshal,
+		"getMapValue": getMapValue,
+
+		// Identificadores y fechas
+		"uuid":       func() string { return uuid.New().String() },
+		"now":        templateNow,
+		"dateAdd":    templateDateAdd,
+		"formatDate": templateFormatDate,
+
+		// Valores aleatorios
+		"randomInt":    templateRandomInt,
+		"randomString": templateRandomString,
+
+		// Matemáticas
+		"add":   func(a, b interface{}) (interface{}, error) { return mathOp("add", a, b) },
+		"sub":   func(a, b interface{}) (interface{}, error) { return mathOp("sub", a, b) },
+		"mul":   func(a, b interface{}) (interface{}, error) { return mathOp("mul", a, b) },
+		"div":   func(a, b interface{}) (interface{}, error) { return mathOp("div", a, b) },
+		"mod":   func(a, b interface{}) (interface{}, error) { return mathOp("mod", a, b) },
+		"max":   func(a, b interface{}) (interface{}, error) { return mathOp("max", a, b) },
+		"min":   func(a, b interface{}) (interface{}, error) { return mathOp("min", a, b) },
+		"round": templateRound,
+
+		// Cadenas de texto
+		"upper":      func(s interface{}) string { return strings.ToUpper(toString(s)) },
+		"lower":      func(s interface{}) string { return strings.ToLower(toString(s)) },
+		"title":      func(s interface{}) string { return toTitleCase(toString(s)) },
+		"camelCase":  func(s interface{}) string { return toCamelCase(toString(s)) },
+		"snakeCase":  func(s interface{}) string { return joinWords(toString(s), "_") },
+		"kebabCase":  func(s interface{}) string { return joinWords(toString(s), "-") },
+		"trim":       func(s interface{}) string { return strings.TrimSpace(toString(s)) },
+		"trimPrefix": func(prefix string, s interface{}) string { return strings.TrimPrefix(toString(s), prefix) },
+		"trimSuffix": func(suffix string, s interface{}) string { return strings.TrimSuffix(toString(s), suffix) },
+		"split":      func(sep string, s interface{}) []string { return strings.Split(toString(s), sep) },
+		"join":       templateJoin,
+		"replace":    func(old, new string, s interface{}) string { return strings.ReplaceAll(toString(s), old, new) },
+		"contains":   func(substr string, s interface{}) bool { return strings.Contains(toString(s), substr) },
+		"hasPrefix":  func(prefix string, s interface{}) bool { return strings.HasPrefix(toString(s), prefix) },
+		"hasSuffix":  func(suffix string, s interface{}) bool { return strings.HasSuffix(toString(s), suffix) },
+
+		// Valores por defecto y construcción de datos (útil para pasar parámetros a partials)
+		"default": templateDefault,
+		"dict":    templateDict,
+		"list":    func(items ...interface{}) []interface{} { return items },
+
+		// Store clave-valor compartido entre solicitudes
+		"store":       newTemplateStore,
+		"storeGet":    defaultStore.Get,
+		"storeSet":    defaultStore.Set,
+		"storeDelete": defaultStore.Delete,
+
+		// Codificación y hashing
+		"base64Encode": func(s interface{}) string { return base64.StdEncoding.EncodeToString([]byte(toString(s))) },
+		"base64Decode": templateBase64Decode,
+		"sha256": func(s interface{}) string {
+			sum := sha256.Sum256([]byte(toString(s)))
+			return hex.EncodeToString(sum[:])
+		},
+
+		// Consultas y conversión de estructuras
+		"jsonPath": jsonPathLookup,
+		"toXml":    templateToXML,
+
+		// Datasets cargados con /configure-mock/datasets
+		"lookup":    templateLookup,
+		"lookupAll": templateLookupAll,
+		"dataset":   templateDataset,
+
+		// Escapado seguro
+		"htmlEscape": func(s interface{}) string { return html.EscapeString(toString(s)) },
+		"jsonEscape": templateJSONEscape,
+	}
+
+	// Generadores de datos falsos (fakeName, fakeEmail...). Se crean por ejecución para que
+	// 'fakeSeed' y 'fakeLocale' solo afecten a la respuesta actual.
+	for name, fn := range newFaker().funcs() {
+		funcs[name] = fn
+	}
+
+	return funcs
+}
+
+// toString convierte cualquier valor a su representación en texto.
+// Los números provenientes de JSON (float64) sin parte decimal se imprimen como enteros.
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// --- Fechas ---
+
+// offsetRegex reconoce cada segmento de un desplazamiento de fecha, por ejemplo "+1d", "-2h30m" o "+1y2mo".
+var offsetRegex = regexp.MustCompile(`([+-]?)(\d+)(ms|mo|y|w|d|h|m|s)`)
+
+// applyDateOffset aplica a t un desplazamiento expresado como una secuencia de <número><unidad>.
+// Unidades soportadas: y (años), mo (meses), w (semanas), d (días), h, m, s y ms.
+// El signo de un segmento se hereda en los siguientes hasta que aparezca otro signo.
+func applyDateOffset(t time.Time, offset string) (time.Time, error) {
+	offset = strings.ReplaceAll(strings.TrimSpace(offset), " ", "")
+	if offset == "" {
+		return t, nil
+	}
+
+	matches := offsetRegex.FindAllStringSubmatchIndex(offset, -1)
+	consumed := 0
+	sign := 1
+	for _, m := range matches {
+		if m[0] != consumed {
+			return t, fmt.Errorf("desplazamiento de fecha inválido: %q", offset)
+		}
+		consumed = m[1]
+
+		switch offset[m[2]:m[3]] {
+		case "-":
+			sign = -1
+		case "+":
+			sign = 1
+		}
+		n, _ := strconv.Atoi(offset[m[4]:m[5]])
+		n *= sign
+
+		switch offset[m[6]:m[7]] {
+		case "y":
+			t = t.AddDate(n, 0, 0)
+		case "mo":
+			t = t.AddDate(0, n, 0)
+		case "w":
+			t = t.AddDate(0, 0, 7*n)
+		case "d":
+			t = t.AddDate(0, 0, n)
+		case "h":
+			t = t.Add(time.Duration(n) * time.Hour)
+		case "m":
+			t = t.Add(time.Duration(n) * time.Minute)
+		case "s":
+			t = t.Add(time.Duration(n) * time.Second)
+		case "ms":
+			t = t.Add(time.Duration(n) * time.Millisecond)
+		}
+	}
+	if consumed != len(offset) {
+		return t, fmt.Errorf("desplazamiento de fecha inválido: %q", offset)
+	}
+	return t, nil
+}
+
+// templateNow devuelve la hora actual en UTC, opcionalmente desplazada (ej. '{{ now "+1d" }}').
+func templateNow(offset ...string) (time.Time, error) {
+	t := time.Now().UTC()
+	if len(offset) > 0 {
+		return applyDateOffset(t, offset[0])
+	}
+	return t, nil
+}
+
+// toTime convierte un valor de plantilla a time.Time.
+// Acepta time.Time, cadenas RFC3339 o "2006-01-02" y números como segundos Unix.
+func toTime(v interface{}) (time.Time, error) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, nil
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("no se pudo interpretar la fecha %q", val)
+	case int, int64, float64:
+		f, _ := toFloat(val)
+		return time.Unix(int64(f), 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("tipo de fecha no soportado: %T", v)
+}
+
+// templateDateAdd desplaza una fecha: '{{ now | dateAdd "-2h" }}'.
+func templateDateAdd(offset string, v interface{}) (time.Time, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return t, err
+	}
+	return applyDateOffset(t, offset)
+}
+
+// namedDateLayouts contiene alias legibles para los formatos de fecha más comunes.
+var namedDateLayouts = map[string]string{
+	"iso":      time.RFC3339,
+	"rfc3339":  time.RFC3339,
+	"rfc1123":  time.RFC1123,
+	"date":     "2006-01-02",
+	"time":     "15:04:05",
+	"datetime": "2006-01-02 15:04:05",
+}
+
+// templateFormatDate formatea una fecha con un layout de Go o un alias ("iso", "date", "unix", "unixMillis"...).
+func templateFormatDate(layout string, v interface{}) (string, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return "", err
+	}
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10), nil
+	case "unixMillis":
+		return strconv.FormatInt(t.UnixMilli(), 10), nil
+	}
+	if named, ok := namedDateLayouts[layout]; ok {
+		layout = named
+	}
+	return t.Format(layout), nil
+}
+
+// --- Valores aleatorios ---
+
+// templateRandomInt devuelve un entero aleatorio en el rango [min, max] (ambos incluidos).
+func templateRandomInt(minVal, maxVal interface{}) (int64, error) {
+	lo, err := toInt(minVal)
+	if err != nil {
+		return 0, err
+	}
+	hi, err := toInt(maxVal)
+	if err != nil {
+		return 0, err
+	}
+	if hi < lo {
+		return 0, fmt.Errorf("randomInt: el máximo (%d) es menor que el mínimo (%d)", hi, lo)
+	}
+	// El tamaño del rango se calcula con big.Int porque hi-lo+1 desborda int64 en rangos muy amplios
+	span := new(big.Int).Sub(big.NewInt(hi), big.NewInt(lo))
+	span.Add(span, big.NewInt(1))
+	n, err := rand.Int(rand.Reader, span)
+	if err != nil {
+		return 0, err
+	}
+	return n.Add(n, big.NewInt(lo)).Int64(), nil
+}
+
+// randomCharsets define los juegos de caracteres aceptados por randomString.
+var randomCharsets = map[string]string{
+	"alphanumeric": "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	"alpha":        "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"numeric":      "0123456789",
+	"hex":          "0123456789abcdef",
+	"upper":        "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"lower":        "abcdefghijklmnopqrstuvwxyz",
+}
+
+// templateRandomString genera una cadena aleatoria de longitud n.
+// El segundo argumento opcional elige el juego de caracteres (por defecto "alphanumeric").
+func templateRandomString(length interface{}, charset ...string) (string, error) {
+	n, err := toInt(length)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n > 4096 {
+		return "", fmt.Errorf("randomString: la longitud debe estar entre 0 y 4096")
+	}
+	chars := randomCharsets["alphanumeric"]
+	if len(charset) > 0 {
+		c, ok := randomCharsets[charset[0]]
+		if !ok {
+			return "", fmt.Errorf("randomString: juego de caracteres desconocido %q", charset[0])
+		}
+		chars = c
+	}
+
+	out := make([]byte, n)
+	max := big.NewInt(int64(len(chars)))
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		out[i] = chars[idx.Int64()]
+	}
+	return string(out), nil
+}
+
+// --- Matemáticas ---
+
+// toFloat convierte números y cadenas numéricas a float64.
+func toFloat(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case float64:
+		return val, nil
+	case float32:
+		return float64(val), nil
+	case int:
+		return float64(val), nil
+	case int64:
+		return float64(val), nil
+	case int32:
+		return float64(val), nil
+	case json.Number:
+		return val.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q no es un número", val)
+		}
+		return f, nil
+	case nil:
+		return 0, fmt.Errorf("se esperaba un número y se recibió un valor vacío")
+	}
+	return 0, fmt.Errorf("se esperaba un número y se recibió %T", v)
+}
+
+// toInt convierte un valor numérico a int64, rechazando valores con parte decimal o fuera del rango de int64.
+// Los enteros se convierten directamente para no perder precisión al pasar por float64.
+func toInt(v interface{}) (int64, error) {
+	switch val := v.(type) {
+	case int:
+		return int64(val), nil
+	case int64:
+		return val, nil
+	case int32:
+		return int64(val), nil
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n, nil
+		}
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("se esperaba un entero y se recibió %v", v)
+	}
+	return int64(f), nil
+}
+
+// isIntegral indica si ambos operandos son números enteros.
+func isIntegral(a, b float64) bool {
+	return a == math.Trunc(a) && b == math.Trunc(b)
+}
+
+// mathOp aplica una operación aritmética. Si ambos operandos son enteros el resultado es int64
+// (salvo en 'div' con resto), de lo contrario float64.
+func mathOp(op string, a, b interface{}) (interface{}, error) {
+	x, err := toFloat(a)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	y, err := toFloat(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	var r float64
+	switch op {
+	case "add":
+		r = x + y
+	case "sub":
+		r = x - y
+	case "mul":
+		r = x * y
+	case "div":
+		if y == 0 {
+			return nil, fmt.Errorf("div: división entre cero")
+		}
+		r = x / y
+	case "mod":
+		if y == 0 {
+			return nil, fmt.Errorf("mod: división entre cero")
+		}
+		r = math.Mod(x, y)
+	case "max":
+		r = math.Max(x, y)
+	case "min":
+		r = math.Min(x, y)
+	}
+
+	if isIntegral(x, y) && r == math.Trunc(r) {
+		return int64(r), nil
+	}
+	return r, nil
+}
+
+// templateRound redondea un número a la cantidad de decimales indicada: '{{ round 2 .Request.Body.total }}'.
+func templateRound(decimals interface{}, v interface{}) (float64, error) {
+	d, err := toInt(decimals)
+	if err != nil {
+		return 0, err
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	p := math.Pow(10, float64(d))
+	return math.Round(f*p) / p, nil
+}
+
+// --- Cadenas ---
+
+// splitWords separa una cadena en palabras considerando espacios, guiones, guiones bajos y cambios de mayúsculas.
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == ' ' || r == '-' || r == '_' || r == '.':
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+		case unicode.IsUpper(r) && len(current) > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			words = append(words, string(current))
+			current = []rune{r}
+		default:
+			current = append(current, r)
+		}
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// joinWords convierte una cadena a minúsculas uniendo sus palabras con el separador dado (snake_case, kebab-case).
+func joinWords(s, sep string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, sep)
+}
+
+// capitalize pone en mayúscula la primera letra de una palabra y el resto en minúsculas.
+func capitalize(w string) string {
+	runes := []rune(strings.ToLower(w))
+	if len(runes) == 0 {
+		return ""
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// toTitleCase pone en mayúscula la primera letra de cada palabra separada por espacios.
+func toTitleCase(s string) string {
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		fields[i] = capitalize(f)
+	}
+	return strings.Join(fields, " ")
+}
+
+// toCamelCase convierte una cadena a camelCase.
+func toCamelCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = capitalize(w)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// templateJoin une los elementos de una lista (de cualquier tipo) con un separador.
+func templateJoin(sep string, list interface{}) (string, error) {
+	if list == nil {
+		return "", nil
+	}
+	rv := reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: se esperaba una lista y se recibió %T", list)
+	}
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = toString(rv.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// --- Valores por defecto ---
+
+// isEmptyValue indica si un valor se considera vacío para la función default.
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// templateDefault devuelve el valor si no está vacío; en caso contrario, el valor por defecto.
+// Se usa en pipelines: '{{ .Request.Query.page | default "1" }}'.
+func templateDefault(def interface{}, v ...interface{}) interface{} {
+	if len(v) == 0 || isEmptyValue(v[0]) {
+		return def
+	}
+	return v[0]
+}
+
+// templateDict construye un mapa a partir de pares clave-valor:
+// '{{template "errorEnvelope" (dict "code" 404 "message" "No encontrado")}}'.
+func templateDict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: se esperaba un número par de argumentos")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: las claves deben ser strings y se recibió %T", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// --- Codificación ---
+
+// templateBase64Decode decodifica una cadena en Base64 estándar (o URL-safe si la primera falla).
+func templateBase64Decode(s interface{}) (string, error) {
+	str := toString(s)
+	if b, err := base64.StdEncoding.DecodeString(str); err == nil {
+		return string(b), nil
+	}
+	b, err := base64.URLEncoding.DecodeString(str)
+	if err != nil {
+		return "", fmt.Errorf("base64Decode: entrada inválida")
+	}
+	return string(b), nil
+}
+
+// templateJSONEscape escapa un valor para insertarlo dentro de un string JSON (sin las comillas exteriores).
+func templateJSONEscape(s interface{}) (string, error) {
+	b, err := json.Marshal(toString(s))
+	if err != nil {
+		return "", err
+	}
+	return string(b[1 : len(b)-1]), nil
+}
+
+// --- JSONPath ---
+
+// jsonPathLookup obtiene un valor dentro de una estructura JSON (o de un texto JSON) usando una ruta simple:
+// '{{ jsonPath .Request.Body "$.items[0].id" }}'. Soporta notación con puntos, índices
+// entre corchetes y claves entre comillas ("$['x-id']"). Devuelve nil si la ruta no existe.
+func jsonPathLookup(data interface{}, path string) (interface{}, error) {
+	tokens, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Si se recibe el body sin procesar (ej. '.Request.RawBody'), se parsea como JSON
+	if raw, ok := data.(string); ok {
+		if err := json.Unmarshal([]byte(raw), &data); err != nil {
+			return nil, fmt.Errorf("jsonPath: el valor no es un JSON válido")
+		}
+	}
+
+	current := data
+	for _, tok := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[tok]
+		case map[string]string:
+			current = node[tok]
+		case []interface{}:
+			idx, err := strconv.Atoi(tok)
+			if err != nil {
+				return nil, nil
+			}
+			if idx < 0 {
+				idx += len(node)
+			}
+			if idx < 0 || idx >= len(node) {
+				return nil, nil
+			}
+			current = node[idx]
+		default:
+			// Permite recorrer tipos con nombre como models.RequestBody
+			rv := reflect.ValueOf(current)
+			if rv.Kind() == reflect.Map && reflect.TypeOf(tok).ConvertibleTo(rv.Type().Key()) {
+				v := rv.MapIndex(reflect.ValueOf(tok).Convert(rv.Type().Key()))
+				if !v.IsValid() {
+					return nil, nil
+				}
+				current = v.Interface()
+				continue
+			}
+			return nil, nil
+		}
+		if current == nil {
+			return nil, nil
+		}
+	}
+	return current, nil
+}
+
+// parseJSONPath divide una ruta JSONPath simplificada en sus segmentos.
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+
+	var tokens []string
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("jsonPath: segmento vacío en %q", path)
+			}
+			tokens = append(tokens, path[start:i])
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonPath: falta ']' en %q", path)
+			}
+			inner := strings.Trim(path[i+1:i+end], `'"`)
+			tokens = append(tokens, inner)
+			i += end + 1
+		default:
+			// Permite rutas sin '$.' inicial, por ejemplo "items[0].id"
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			tokens = append(tokens, path[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// --- Datasets ---
+
+// templateLookup devuelve el primer registro del dataset cuyo campo coincide con el valor:
+// '{{ (lookup "customers" "id" .Request.PathParams.id).name }}'. Si no hay coincidencias devuelve un mapa vacío.
+func templateLookup(name, field string, value interface{}) (map[string]interface{}, error) {
+	matches, err := templateLookupAll(name, field, value)
+	if err != nil || len(matches) == 0 {
+		return map[string]interface{}{}, err
+	}
+	return matches[0], nil
+}
+
+// templateLookupAll devuelve todos los registros del dataset cuyo campo coincide con el valor.
+func templateLookupAll(name, field string, value interface{}) ([]map[string]interface{}, error) {
+	matches, ok := storage.LookupDataset(name, field, toString(value))
+	if !ok {
+		return nil, fmt.Errorf("lookup: dataset '%s' no encontrado", name)
+	}
+	return matches, nil
+}
+
+// templateDataset devuelve todos los registros de un dataset: '{{ range dataset "customers" }}...{{ end }}'.
+func templateDataset(name string) ([]map[string]interface{}, error) {
+	records, ok := storage.GetDataset(name)
+	if !ok {
+		return nil, fmt.Errorf("dataset: dataset '%s' no encontrado", name)
+	}
+	return records, nil
+}
+
+// --- XML ---
+
+// templateToXML serializa mapas, listas y valores escalares a XML: '{{ toXml "order" .Request.Body }}'.
+// Las claves de los mapas se ordenan alfabéticamente; los elementos de una lista se emiten como <item>.
+func templateToXML(root string, v interface{}) (string, error) {
+	var sb strings.Builder
+	if err := writeXMLElement(&sb, root, v); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// writeXMLElement escribe recursivamente un elemento XML con su contenido escapado. Los nombres que no son
+// nombres XML válidos (por ejemplo claves con espacios o '<') se rechazan para no generar XML mal formado.
+func writeXMLElement(sb *strings.Builder, name string, v interface{}) error {
+	if name == "" {
+		name = "root"
+	}
+	if !isXMLName(name) {
+		return fmt.Errorf("toXml: '%s' no es un nombre de elemento XML válido", name)
+	}
+	sb.WriteString("<" + name + ">")
+
+	rv := reflect.ValueOf(v)
+	switch {
+	case v == nil:
+	case rv.Kind() == reflect.Map:
+		// Las claves se ordenan por su texto y se usan tal cual para leer el valor, sin conversiones
+		mapKeys := rv.MapKeys()
+		keys := make([]string, len(mapKeys))
+		for i, k := range mapKeys {
+			keys[i] = toString(k.Interface())
+		}
+		sort.Sort(mapKeysByText{names: keys, values: mapKeys})
+		for i, k := range mapKeys {
+			if err := writeXMLElement(sb, keys[i], rv.MapIndex(k).Interface()); err != nil {
+				return err
+			}
+		}
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := writeXMLElement(sb, "item", rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := xml.EscapeText(sb, []byte(toString(v))); err != nil {
+			return err
+		}
+	}
+
+	sb.WriteString("</" + name + ">")
+	return nil
+}
+
+// mapKeysByText ordena las claves de un mapa por su texto manteniendo junto el valor reflect de cada una.
+type mapKeysByText struct {
+	names  []string
+	values []reflect.Value
+}
+
+func (k mapKeysByText) Len() int           { return len(k.names) }
+func (k mapKeysByText) Less(i, j int) bool { return k.names[i] < k.names[j] }
+func (k mapKeysByText) Swap(i, j int) {
+	k.names[i], k.names[j] = k.names[j], k.names[i]
+	k.values[i], k.values[j] = k.values[j], k.values[i]
+}
+
+// isXMLName verifica si un texto cumple la producción Name de XML 1.0 (quinta edición).
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !isXMLNameStartChar(r) && (i == 0 || !isXMLNameChar(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isXMLNameStartChar verifica si un carácter puede iniciar un nombre XML.
+func isXMLNameStartChar(r rune) bool {
+	switch {
+	case r == ':' || r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z'):
+		return true
+	case r >= 0xC0 && r <= 0xD6, r >= 0xD8 && r <= 0xF6, r >= 0xF8 && r <= 0x2FF,
+		r >= 0x370 && r <= 0x37D, r >= 0x37F && r <= 0x1FFF, r >= 0x200C && r <= 0x200D,
+		r >= 0x2070 && r <= 0x218F, r >= 0x2C00 && r <= 0x2FEF, r >= 0x3001 && r <= 0xD7FF,
+		r >= 0xF900 && r <= 0xFDCF, r >= 0xFDF0 && r <= 0xFFFD, r >= 0x10000 && r <= 0xEFFFF:
+		return true
+	}
+	return false
+}
+
+// isXMLNameChar verifica si un carácter puede aparecer después del primero en un nombre XML.
+func isXMLNameChar(r rune) bool {
+	switch {
+	case isXMLNameStartChar(r):
+		return true
+	case r == '-' || r == '.' || (r >= '0' && r <= '9') || r == 0xB7,
+		r >= 0x300 && r <= 0x36F, r >= 0x203F && r <= 0x2040:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"backend/models"
+)
+
+// execFuncs ejecuta una plantilla Go con la biblioteca de funciones y devuelve su salida.
+func execFuncs(t *testing.T, tmpl string, data interface{}) (string, error) {
+	t.Helper()
+	parsed, err := template.New(t.Name()).Funcs(templateFuncMap()).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = parsed.Execute(&sb, data)
+	return sb.String(), err
+}
+
+// checkFuncs ejecuta cada plantilla del mapa y compara su salida con la esperada.
+func checkFuncs(t *testing.T, cases map[string]string, data interface{}) {
+	t.Helper()
+	for tmpl, want := range cases {
+		got, err := execFuncs(t, tmpl, data)
+		if err != nil {
+			t.Errorf("%s: error inesperado: %v", tmpl, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s = %q, se esperaba %q", tmpl, got, want)
+		}
+	}
+}
+
+func TestStringFuncs(t *testing.T) {
+	checkFuncs(t, map[string]string{
+		`{{upper "ana maría"}}`:                                      "ANA MARÍA",
+		`{{lower "ÁRBOL"}}`:                                          "árbol",
+		`{{title "ana maría"}}`:                                      "Ana María",
+		`{{camelCase "order-total value"}}`:                          "orderTotalValue",
+		`{{snakeCase "orderTotal"}}`:                                 "order_total",
+		`{{kebabCase "Order Total"}}`:                                "order-total",
+		`{{trim "  x  "}}`:                                           "x",
+		`{{"v1/users.json" | trimPrefix "v1" | trimSuffix ".json"}}`: "/users",
+		`{{join "," (split ";" "a;b;c")}}`:                           "a,b,c",
+		`{{replace "-" "+" "a-b-c"}}`:                                "a+b+c",
+		`{{contains "ell" "hello"}}`:                                 "true",
+		`{{.missing | default "anónimo"}}`:                           "anónimo",
+		`{{default "x" 0}}`:                                          "0",
+		`{{default "x" false}}`:                                      "x",
+		`{{$d := dict "a" 1 "b" 2}}{{$d.b}}`:                         "2",
+	}, map[string]interface{}{})
+
+	if _, err := execFuncs(t, `{{dict "a"}}`, nil); err == nil || !strings.Contains(err.Error(), "dict") {
+		t.Errorf("dict con una cantidad impar de argumentos: se obtuvo %v", err)
+	}
+}
+
+func TestMathFuncs(t *testing.T) {
+	body := models.RequestBody{"qty": float64(3), "total": 10.456}
+	checkFuncs(t, map[string]string{
+		`{{add .qty 2}}`:     "5",
+		`{{sub 2 .qty}}`:     "-1",
+		`{{mul .qty 1.5}}`:   "4.5",
+		`{{div 7 2}}`:        "3.5",
+		`{{div 8 2}}`:        "4",
+		`{{mod 7 3}}`:        "1",
+		`{{max .qty 9}}`:     "9",
+		`{{min "4" .qty}}`:   "3",
+		`{{round 2 .total}}`: "10.46",
+		`{{round 0 .total}}`: "10",
+	}, body)
+
+	for _, tmpl := range []string{`{{div 1 0}}`, `{{mod 1 0}}`} {
+		if _, err := execFuncs(t, tmpl, nil); err == nil || !strings.Contains(err.Error(), "división entre cero") {
+			t.Errorf("%s: se esperaba un error de división entre cero, se obtuvo %v", tmpl, err)
+		}
+	}
+	if _, err := execFuncs(t, `{{add "a" 1}}`, nil); err == nil {
+		t.Error(`add "a" 1: se esperaba un error`)
+	}
+}
+
+func TestApplyDateOffset(t *testing.T) {
+	base := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		offset string
+		want   time.Time
+	}{
+		{"", base},
+		{"+1d", base.AddDate(0, 0, 1)},
+		{"-2h30m", base.Add(-2*time.Hour - 30*time.Minute)},
+		{"+1y2mo", base.AddDate(1, 2, 0)},
+		{"+1w -1d", base.AddDate(0, 0, 6)},
+		{"+500ms", base.Add(500 * time.Millisecond)},
+	}
+	for _, tt := range tests {
+		got, err := applyDateOffset(base, tt.offset)
+		if err != nil {
+			t.Errorf("applyDateOffset(%q): error inesperado: %v", tt.offset, err)
+		} else if !got.Equal(tt.want) {
+			t.Errorf("applyDateOffset(%q) = %v, se esperaba %v", tt.offset, got, tt.want)
+		}
+	}
+
+	for _, offset := range []string{"1d+", "mañana", "+1x"} {
+		if _, err := applyDateOffset(base, offset); err == nil {
+			t.Errorf("applyDateOffset(%q): se esperaba un error", offset)
+		}
+	}
+}
+
+func TestDateFuncs(t *testing.T) {
+	checkFuncs(t, map[string]string{
+		`{{formatDate "2006-01-02" "2024-03-05T10:00:00Z"}}`:                       "2024-03-05",
+		`{{dateAdd "+1mo-1d" "2024-01-31T00:00:00Z" | formatDate "2006-01-02"}}`:   "2024-03-01",
+		`{{dateAdd "-1h" "2024-03-05T00:00:00Z" | formatDate "2006-01-02T15:04"}}`: "2024-03-04T23:00",
+	}, nil)
+
+	got, err := execFuncs(t, `{{now "+1d" | formatDate "2006-01-02"}}`, nil)
+	if want := time.Now().AddDate(0, 0, 1).Format("2006-01-02"); err != nil || got != want {
+		t.Errorf(`now "+1d" = %q (%v), se esperaba %q`, got, err, want)
+	}
+}
+
+func TestRandomFuncs(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		n, err := templateRandomInt(-3, 3)
+		if err != nil || n < -3 || n > 3 {
+			t.Fatalf("randomInt -3 3 = %d (%v)", n, err)
+		}
+	}
+	if n, err := templateRandomInt(7, 7); err != nil || n != 7 {
+		t.Errorf("randomInt 7 7 = %d (%v), se esperaba 7", n, err)
+	}
+	if _, err := templateRandomInt(5, 1); err == nil {
+		t.Error("randomInt 5 1: se esperaba un error")
+	}
+	for _, bounds := range [][2]int64{{math.MinInt64, math.MaxInt64}, {math.MinInt64, 0}, {-1, math.MaxInt64}} {
+		n, err := templateRandomInt(bounds[0], bounds[1])
+		if err != nil || n < bounds[0] || n > bounds[1] {
+			t.Errorf("randomInt %d %d = %d (%v)", bounds[0], bounds[1], n, err)
+		}
+	}
+	if _, err := execFuncs(t, `{{randomInt -9223372036854775808 9223372036854775807}}`, nil); err != nil {
+		t.Errorf("randomInt con el rango completo de int64: %v", err)
+	}
+
+	s, err := templateRandomString(32, "hex")
+	if err != nil || len(s) != 32 || strings.Trim(s, randomCharsets["hex"]) != "" {
+		t.Errorf("randomString 32 hex = %q (%v)", s, err)
+	}
+	if _, err := templateRandomString(8, "emoji"); err == nil {
+		t.Error("randomString con un juego de caracteres desconocido: se esperaba un error")
+	}
+	if _, err := templateRandomString(5000); err == nil {
+		t.Error("randomString 5000: se esperaba un error")
+	}
+}
+
+func TestEncodingFuncs(t *testing.T) {
+	checkFuncs(t, map[string]string{
+		`{{base64Encode "hola"}}`:                "aG9sYQ==",
+		`{{base64Encode "hola" | base64Decode}}`: "hola",
+		`{{sha256 "abc"}}`:                       "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		`{{jsonEscape "a\"b\n"}}`:                `a\"b\n`,
+		`{{htmlEscape "<b>&"}}`:                  "&lt;b&gt;&amp;",
+	}, nil)
+
+	if _, err := execFuncs(t, `{{base64Decode "%%"}}`, nil); err == nil {
+		t.Error("base64Decode con un valor inválido: se esperaba un error")
+	}
+}
+
+func TestJSONPathLookup(t *testing.T) {
+	body := models.RequestBody{
+		"items": []interface{}{map[string]interface{}{"id": "a1"}, map[string]interface{}{"id": "a2"}},
+		"user":  map[string]interface{}{"x-id": "u7"},
+	}
+	tests := []struct {
+		data interface{}
+		path string
+		want interface{}
+	}{
+		{body, "$.items[1].id", "a2"},
+		{body, "items[-1].id", "a2"},
+		{body, "$.user['x-id']", "u7"},
+		{body, "$.items[5].id", nil},
+		{body, "$.nope.id", nil},
+		{`{"user":{"x-id":"u7"}}`, `$["user"]["x-id"]`, "u7"},
+	}
+	for _, tt := range tests {
+		got, err := jsonPathLookup(tt.data, tt.path)
+		if err != nil {
+			t.Errorf("jsonPath %q: error inesperado: %v", tt.path, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("jsonPath %q = %v, se esperaba %v", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := jsonPathLookup(body, "$.items[0"); err == nil || !strings.Contains(err.Error(), "falta ']'") {
+		t.Errorf("ruta sin cierre: se obtuvo %v", err)
+	}
+	if _, err := jsonPathLookup("{no es json", "$.a"); err == nil {
+		t.Error("texto que no es JSON: se esperaba un error")
+	}
+}
+
+func TestTemplateToXML(t *testing.T) {
+	got, err := templateToXML("order", map[string]interface{}{
+		"id":    "o-1",
+		"items": []interface{}{"x", 2.0},
+		"note":  "<urgente> & frágil",
+		"extra": nil,
+	})
+	want := "<order><extra></extra><id>o-1</id><items><item>x</item><item>2</item></items><note>&lt;urgente&gt; &amp; frágil</note></order>"
+	if err != nil || got != want {
+		t.Errorf("toXml = %q (%v)\nse esperaba %q", got, err, want)
+	}
+
+	if got, _ := templateToXML("", "x"); got != "<root>x</root>" {
+		t.Errorf("toXml sin raíz = %q, se esperaba <root>x</root>", got)
+	}
+}
+
+func TestJSONPathNonStringKeys(t *testing.T) {
+	// Un mapa con claves que no son texto no se puede recorrer por nombre; no debe entrar en pánico
+	got, err := jsonPathLookup(map[int]string{1: "uno"}, "$.1")
+	if err != nil || got != nil {
+		t.Errorf("jsonPath sobre map[int]string = %v (%v), se esperaba nil", got, err)
+	}
+}
+
+func TestTemplateToXMLInvalidNames(t *testing.T) {
+	for _, v := range []interface{}{
+		map[string]interface{}{"a b": 1},
+		map[string]interface{}{"ok": map[string]interface{}{"a<b": 1}},
+		map[int]string{1: "uno"},
+	} {
+		if got, err := templateToXML("r", v); err == nil || !strings.Contains(err.Error(), "no es un nombre de elemento XML válido") {
+			t.Errorf("toXml %v = %q (%v), se esperaba un error de nombre", v, got, err)
+		}
+	}
+}
+
+func TestIsXMLName(t *testing.T) {
+	names := map[string]bool{
+		"order":    true,
+		"_id":      true,
+		"ns:item":  true,
+		"item-2.v": true,
+		"número":   true,
+		"2items":   false,
+		"-a":       false,
+		"a b":      false,
+		"a<b":      false,
+		"":         false,
+		"×tag":     false,
+	}
+	for name, want := range names {
+		if got := isXMLName(name); got != want {
+			t.Errorf("isXMLName(%q) = %v, se esperaba %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"backend/storage"
+)
+
+// templateStore da acceso al store clave-valor desde las plantillas. En Go templates se usan sus
+// métodos exportados ('{{store.Set "lastOrder" .Request.Body.id}}', '{{(store "orders").Get "last"}}');
+// en Handlebars los mismos métodos se escriben en minúsculas ('{{store.set "lastOrder" request.body.id}}').
+// Las plantillas Go también aceptan la forma en minúsculas: capitalizeStoreCalls la reescribe antes de parsear.
+type templateStore struct {
+	namespace string
+}
+
+// newTemplateStore crea el acceso al store para el namespace indicado (o el namespace por defecto).
+func newTemplateStore(namespace ...string) (templateStore, error) {
+	if len(namespace) > 1 {
+		return templateStore{}, fmt.Errorf("store: se esperaba como máximo un namespace")
+	}
+	if len(namespace) == 0 || namespace[0] == "" {
+		return templateStore{namespace: storage.DefaultStoreNamespace}, nil
+	}
+	return templateStore{namespace: namespace[0]}, nil
+}
+
+// Get devuelve el valor de una clave, o el valor por defecto indicado (cadena vacía si se omite) cuando no existe.
+func (s templateStore) Get(key string, def ...interface{}) interface{} {
+	if value, ok := storage.GetStoreValue(s.namespace, key); ok {
+		return value
+	}
+	if len(def) > 0 {
+		return def[0]
+	}
+	return ""
+}
+
+// Set guarda el valor de una clave. Devuelve una cadena vacía para no alterar la salida de la plantilla.
+func (s templateStore) Set(key string, value interface{}) (string, error) {
+	if err := storage.SetStoreValue(s.namespace, key, value); err != nil {
+		return "", fmt.Errorf("store: no se pudo guardar la clave '%s': %w", key, err)
+	}
+	return "", nil
+}
+
+// Delete elimina una clave. Devuelve una cadena vacía para no alterar la salida de la plantilla.
+func (s templateStore) Delete(key string) string {
+	storage.DeleteStoreValue(s.namespace, key)
+	return ""
+}
+
+// Has indica si existe una clave.
+func (s templateStore) Has(key string) bool {
+	_, ok := storage.GetStoreValue(s.namespace, key)
+	return ok
+}
+
+// goTemplateActionRegex encuentra las acciones '{{ ... }}' de una plantilla Go.
+var goTemplateActionRegex = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// templateLiteralRegex encuentra las cadenas entre comillas o entre acentos graves dentro de una acción.
+var templateLiteralRegex = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`")
+
+// lowercaseStoreCallRegex encuentra los métodos del store escritos en minúsculas ('store.set',
+// '(store "orders").get'), pero no campos con el mismo nombre como '.Request.Body.store.set' o '$store.set'.
+var lowercaseStoreCallRegex = regexp.MustCompile(`(?:^|[^\w.$])(store|\(store(?:\s[^()]*)?\))\.(get|set|delete|has)\b`)
+
+// capitalizeStoreCalls reescribe en las acciones de una plantilla Go los métodos del store escritos en
+// minúsculas, como en Handlebars y en los scripts, a los métodos exportados que Go templates puede invocar:
+// '{{store.set "k" v}}' pasa a '{{store.Set "k" v}}'. El texto fuera de las acciones y las cadenas
+// literales no se modifican.
+func capitalizeStoreCalls(src string) string {
+	return goTemplateActionRegex.ReplaceAllStringFunc(src, func(action string) string {
+		literals := templateLiteralRegex.FindAllStringIndex(action, -1)
+		insideLiteral := func(pos int) bool {
+			for _, lit := range literals {
+				if pos >= lit[0] && pos < lit[1] {
+					return true
+				}
+			}
+			return false
+		}
+
+		var sb strings.Builder
+		last := 0
+		for _, m := range lowercaseStoreCallRegex.FindAllStringSubmatchIndex(action, -1) {
+			receiverStart, methodStart := m[2], m[4]
+			if insideLiteral(receiverStart) {
+				continue
+			}
+			sb.WriteString(action[last:methodStart])
+			sb.WriteString(strings.ToUpper(action[methodStart : methodStart+1]))
+			last = methodStart + 1
+		}
+		sb.WriteString(action[last:])
+		return sb.String()
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"testing"
+
+	"backend/storage"
+)
+
+func TestCapitalizeStoreCalls(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{`{{store.set "k" .Request.Body.id}}`, `{{store.Set "k" .Request.Body.id}}`},
+		{`{{store.get "k" 0}}-{{store.has "k"}}{{store.delete "k"}}`, `{{store.Get "k" 0}}-{{store.Has "k"}}{{store.Delete "k"}}`},
+		{`{{(store "orders").set "7" .}}`, `{{(store "orders").Set "7" .}}`},
+		{`{{if store.has "k"}}{{json (store.get "k")}}{{end}}`, `{{if store.Has "k"}}{{json (store.Get "k")}}{{end}}`},
+		{`{{ store.Set "k" 1 }}`, `{{ store.Set "k" 1 }}`},
+		{`{{.Request.Body.store.get}} {{$store.get}} {{"store.set"}}`, `{{.Request.Body.store.get}} {{$store.get}} {{"store.set"}}`},
+		{`store.set fuera de las acciones`, `store.set fuera de las acciones`},
+		{`{{store.settle}}`, `{{store.settle}}`},
+	}
+	for _, tt := range tests {
+		if got := capitalizeStoreCalls(tt.src); got != tt.want {
+			t.Errorf("capitalizeStoreCalls(%q) = %q, se esperaba %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateStoreLowercaseMethods(t *testing.T) {
+	t.Cleanup(storage.ClearStore)
+
+	src := `{{store.set "lastOrder" .id}}{{(store "orders").set "o-1" .id}}` +
+		`{{store.get "lastOrder"}},{{(store "orders").get "o-1"}},{{store.has "nope"}},{{store.get "nope" "vacío"}}`
+	got, err := renderTemplate("store", src, templateFuncMap(), map[string]interface{}{"id": 42})
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if want := "42,42,false,vacío"; got != want {
+		t.Errorf("se obtuvo %q, se esperaba %q", got, want)
+	}
+	if err := checkTemplateSyntax(`{{store.delete "lastOrder"}}`, ""); err != nil {
+		t.Errorf("checkTemplateSyntax con store.delete: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"os"
+	"strings"
+
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Formas de seleccionar el workspace de una solicitud
+const (
+	workspaceHeader            = "X-Mock-Workspace"
+	defaultWorkspacePathPrefix = "/ws"
+	workspaceLocalsKey         = "workspace"
+)
+
+// workspacePathPrefix devuelve el prefijo de URL que selecciona el workspace ('/ws' por defecto), con la
+// barra final. Se puede cambiar con la variable de entorno WORKSPACE_PATH_PREFIX; con un valor vacío el
+// prefijo se desactiva y los mocks pueden usar cualquier ruta.
+func workspacePathPrefix() string {
+	prefix, ok := os.LookupEnv("WORKSPACE_PATH_PREFIX")
+	if !ok {
+		prefix = defaultWorkspacePathPrefix
+	}
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix + "/"
+}
+
+// WorkspaceMiddleware determina el workspace de cada solicitud y lo guarda en el contexto. Con un workspace
+// fijo (puerto dedicado) todas las solicitudes usan ese workspace. Si no, se toma del prefijo de la URL
+// '/ws/{nombre}/...' (que se quita antes de enrutar, de modo que '/ws/equipo-a/configure-mock' llega a
+// '/configure-mock'), del header X-Mock-Workspace o, si no se indica, del workspace por defecto.
+func WorkspaceMiddleware(fixed string) fiber.Handler {
+	pathPrefix := workspacePathPrefix()
+	return func(c *fiber.Ctx) error {
+		name := fixed
+		if name == "" {
+			if rest, ok := strings.CutPrefix(c.Path(), pathPrefix); ok && pathPrefix != "" {
+				var path string
+				name, path, _ = strings.Cut(rest, "/")
+				name = strings.Clone(name)
+				c.Path("/" + path)
+			} else if header := strings.TrimSpace(c.Get(workspaceHeader)); header != "" {
+				name = strings.Clone(header)
+			} else {
+				name = storage.DefaultWorkspace
+			}
+		}
+
+		if !partialNameRegex.MatchString(name) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El nombre del workspace es inválido. Debe iniciar con una letra y contener solo letras, números, '.', '-' o '_'.", "workspace": name})
+		}
+		c.Locals(workspaceLocalsKey, name)
+		return c.Next()
+	}
+}
+
+// workspaceOf devuelve el workspace de la solicitud determinado por WorkspaceMiddleware.
+func workspaceOf(c *fiber.Ctx) string {
+	if name, ok := c.Locals(workspaceLocalsKey).(string); ok {
+		return name
+	}
+	return storage.DefaultWorkspace
+}
+
+// GetWorkspaces maneja la solicitud GET /configure-mock/workspaces
+func GetWorkspaces(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"current": workspaceOf(c), "workspaces": storage.GetWorkspaces()})
+}
+
+// DefaultWorkspaceOnly rechaza las solicitudes de administración de los datos globales (partials, datasets,
+// recursos CRUD, store, proxy y grabaciones) que no provienen del workspace por defecto. Esos datos son
+// compartidos por todos los workspaces, por lo que modificarlos desde uno afectaría a los demás.
+func DefaultWorkspaceOnly(c *fiber.Ctx) error {
+	if workspace := workspaceOf(c); workspace != storage.DefaultWorkspace {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Los partials, datasets, recursos, el store, el proxy y las grabaciones son globales y solo se administran desde el workspace por defecto.", "workspace": workspace})
+	}
+	return c.Next()
+}
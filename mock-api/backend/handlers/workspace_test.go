@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"backend/models"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWorkspaceGlobalHandlers(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	storage.InitMockStorage()
+
+	var upstreamHits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHits.Add(1)
+		w.Write([]byte("upstream"))
+	}))
+	defer upstream.Close()
+
+	// El workspace equipo-a solo tiene un mock propio; el recurso y el proxy pertenecen a default
+	if err := storage.AddMockConfig("equipo-a", models.MockConfig{Id: "propio", Path: "/propio", Method: "GET", ResponseStatusCode: 200, ContentType: "text/plain"}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.DeleteMockConfigRevision("equipo-a", "propio", 1, "test") })
+	resource := models.ResourceConfig{Name: "productos", Path: "/productos", IdField: "id", Seed: []map[string]interface{}{{"id": "1"}}}
+	if err := storage.SaveResourceConfig(resource); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.DeleteResourceConfig(resource.Name) })
+	if err := storage.SaveProxyConfig(models.ProxyConfig{DefaultUpstream: upstream.URL}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.SaveProxyConfig(models.ProxyConfig{}) })
+
+	app := fiber.New()
+	app.Use(WorkspaceMiddleware(""))
+	app.All("/*", ExecuteMock)
+	check := func(path string, want int) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("GET %s: código %d, se esperaba %d", path, resp.StatusCode, want)
+		}
+	}
+
+	check("/productos/1", fiber.StatusOK)
+	check("/ws/equipo-a/productos/1", fiber.StatusNotFound)
+	check("/otra", fiber.StatusOK)
+	check("/ws/equipo-a/otra", fiber.StatusNotFound)
+	if hits := upstreamHits.Load(); hits != 1 {
+		t.Errorf("el upstream del proxy recibió %d solicitudes, se esperaba 1", hits)
+	}
+
+	// Durante una grabación de default las solicitudes de equipo-a no se reenvían ni se graban
+	if err := storage.StartRecording(models.RecordingConfig{Target: upstream.URL}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.StopRecording() })
+	check("/grabada", fiber.StatusOK)
+	check("/ws/equipo-a/propio", fiber.StatusOK)
+	check("/ws/equipo-a/grabada", fiber.StatusNotFound)
+	if status := storage.GetRecordingStatus(); status.Recorded != 1 {
+		t.Errorf("la grabación tiene %d solicitudes, se esperaba 1", status.Recorded)
+	}
+	if hits := upstreamHits.Load(); hits != 2 {
+		t.Errorf("el upstream recibió %d solicitudes, se esperaban 2", hits)
+	}
+}
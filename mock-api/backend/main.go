@@ -14,8 +14,60 @@ import (
 )
 
 func main() {
+	// Inicializar el almacenamiento de mocks y de partials de plantillas
+	storage.InitMockStorage()
+	storage.InitPartialStorage()
+	storage.InitResourceStorage()
+	storage.InitDatasetStorage()
+	storage.InitProxyStorage()
+	storage.InitJournalStorage()
+
+	// Puertos dedicados a un workspace, por ejemplo WORKSPACE_PORTS="equipo-a=4001,equipo-b=4002".
+	// Todas las solicitudes recibidas en esos puertos usan el workspace indicado.
+	for _, entry := range strings.Split(os.Getenv("WORKSPACE_PORTS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		workspace, workspacePort, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(workspace) == "" || strings.TrimSpace(workspacePort) == "" {
+			log.Fatalf("Entrada inválida en WORKSPACE_PORTS: '%s'. Formato esperado: workspace=puerto", entry)
+		}
+		workspace = strings.TrimSpace(workspace)
+		storage.RegisterWorkspace(workspace)
+		go listen(newApp(workspace), strings.TrimSpace(workspacePort))
+	}
+
+	// Iniciar el servidor Fiber
+	port := os.Getenv("PORT")
+
+	// Puerto por defecto si no se especifica
+	if port == "" {
+		port = "3000"
+	}
+	listen(newApp(""), port)
+}
+
+// listen inicia el servidor en el puerto indicado y termina el proceso si no puede hacerlo.
+func listen(app *fiber.App, port string) {
+	// Asegurarse de que el puerto tenga el prefijo correcto
+	if !strings.HasPrefix(port, ":") {
+		port = ":" + port
+	}
+
+	log.Printf("Servidor escuchando en el puerto %s", port)
+	if err := app.Listen(port); err != nil {
+		log.Fatalf("Error al iniciar el servidor: %v", err)
+	}
+}
+
+// newApp crea la aplicación Fiber con todas las rutas. Si se indica un workspace, todas las solicitudes
+// usan ese workspace (puerto dedicado); si no, se elige por prefijo '/ws/{nombre}' (WORKSPACE_PATH_PREFIX) o header X-Mock-Workspace.
+func newApp(workspace string) *fiber.App {
 	// Configuración de Fiber con un manejador de errores global
 	app := fiber.New(fiber.Config{
+		// Con varios puertos solo se muestra un banner de inicio
+		DisableStartupMessage: workspace != "",
 
 		// ErrorHandler es una función que se ejecuta cuando un handler retorna un error
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -44,36 +96,94 @@ func main() {
 
 	// Middleware para CORS
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:5173", // Ajusta esto a la URL de tu frontend
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowOrigins:  "http://localhost:5173", // Ajusta esto a la URL de tu frontend
+		AllowHeaders:  "Origin, Content-Type, Accept, If-Match, X-Mock-Author, X-Mock-Workspace",
+		ExposeHeaders: "ETag, X-Total-Count, X-Next-Cursor",
 	}))
 
-	// Inicializar el almacenamiento de mocks
-	storage.InitMockStorage()
+	// Middleware para seleccionar el workspace de la solicitud
+	app.Use(handlers.WorkspaceMiddleware(workspace))
+
+	// Ruta para listar los workspaces
+	app.Get("/configure-mock/workspaces", handlers.GetWorkspaces)
+
+	// Los partials, el store, las grabaciones, el proxy, los datasets y los recursos son globales:
+	// solo se administran desde el workspace por defecto (handlers.DefaultWorkspaceOnly)
+
+	// Rutas para la gestión de partials (fragmentos de plantilla reutilizables)
+	app.Post("/configure-mock/partials", handlers.DefaultWorkspaceOnly, handlers.ConfigurePartial)
+	app.Get("/configure-mock/partials", handlers.DefaultWorkspaceOnly, handlers.GetPartials)
+	app.Get("/configure-mock/partials/:name", handlers.DefaultWorkspaceOnly, handlers.GetPartial)
+	app.Delete("/configure-mock/partials/:name", handlers.DefaultWorkspaceOnly, handlers.DeletePartial)
+
+	// Rutas para inspeccionar y reiniciar escenarios (máquinas de estado entre mocks)
+	app.Get("/configure-mock/scenarios", handlers.GetScenarios)
+	app.Post("/configure-mock/scenarios/reset", handlers.ResetAllScenarios)
+	app.Get("/configure-mock/scenarios/:name", handlers.GetScenario)
+	app.Put("/configure-mock/scenarios/:name/state", handlers.SetScenarioState)
+	app.Post("/configure-mock/scenarios/:name/reset", handlers.ResetScenario)
+
+	// Rutas para consultar y sembrar el store clave-valor usado por plantillas y scripts
+	app.Get("/configure-mock/store", handlers.DefaultWorkspaceOnly, handlers.GetStore)
+	app.Delete("/configure-mock/store", handlers.DefaultWorkspaceOnly, handlers.ClearStore)
+	app.Get("/configure-mock/store/:namespace", handlers.DefaultWorkspaceOnly, handlers.GetStoreNamespace)
+	app.Post("/configure-mock/store/:namespace", handlers.DefaultWorkspaceOnly, handlers.SeedStoreNamespace)
+	app.Delete("/configure-mock/store/:namespace", handlers.DefaultWorkspaceOnly, handlers.ClearStoreNamespace)
+	app.Get("/configure-mock/store/:namespace/:key", handlers.DefaultWorkspaceOnly, handlers.GetStoreValue)
+	app.Put("/configure-mock/store/:namespace/:key", handlers.DefaultWorkspaceOnly, handlers.SetStoreValue)
+	app.Delete("/configure-mock/store/:namespace/:key", handlers.DefaultWorkspaceOnly, handlers.DeleteStoreValue)
+
+	// Rutas para grabar tráfico real y convertirlo en mocks
+	app.Post("/configure-mock/recordings/start", handlers.DefaultWorkspaceOnly, handlers.StartRecording)
+	app.Get("/configure-mock/recordings/status", handlers.DefaultWorkspaceOnly, handlers.GetRecordingStatus)
+	app.Post("/configure-mock/recordings/stop", handlers.DefaultWorkspaceOnly, handlers.StopRecording)
+
+	// Rutas para configurar el reenvío (proxy) de solicitudes sin mock
+	app.Get("/configure-mock/proxy", handlers.DefaultWorkspaceOnly, handlers.GetProxyConfig)
+	app.Put("/configure-mock/proxy", handlers.DefaultWorkspaceOnly, handlers.SetProxyConfig)
+
+	// Rutas para la gestión de datasets (tablas de datos consultables con 'lookup')
+	app.Post("/configure-mock/datasets/:name", handlers.DefaultWorkspaceOnly, handlers.UploadDataset)
+	app.Put("/configure-mock/datasets/:name", handlers.DefaultWorkspaceOnly, handlers.UploadDataset)
+	app.Get("/configure-mock/datasets", handlers.DefaultWorkspaceOnly, handlers.GetDatasets)
+	app.Get("/configure-mock/datasets/:name", handlers.DefaultWorkspaceOnly, handlers.GetDataset)
+	app.Delete("/configure-mock/datasets/:name", handlers.DefaultWorkspaceOnly, handlers.DeleteDataset)
+
+	// Rutas para la gestión de recursos CRUD en memoria
+	app.Post("/configure-mock/resources", handlers.DefaultWorkspaceOnly, handlers.ConfigureResource)
+	app.Get("/configure-mock/resources", handlers.DefaultWorkspaceOnly, handlers.GetResources)
+	app.Get("/configure-mock/resources/:name", handlers.DefaultWorkspaceOnly, handlers.GetResource)
+	app.Post("/configure-mock/resources/:name/reset", handlers.DefaultWorkspaceOnly, handlers.ResetResource)
+	app.Delete("/configure-mock/resources/:name", handlers.DefaultWorkspaceOnly, handlers.DeleteResource)
+
+	// Ruta para explicar qué mock respondería a una solicitud de ejemplo y por qué
+	app.Post("/configure-mock/explain", handlers.ExplainMatch)
+
+	// Rutas para exportar e importar conjuntos completos de mocks
+	app.Get("/configure-mock/export", handlers.ExportMocks)
+	app.Post("/configure-mock/import", handlers.ImportMocks)
+
+	// Rutas para habilitar y deshabilitar de una vez los mocks con una etiqueta
+	app.Post("/configure-mock/tags/:tag/enable", handlers.EnableMocksByTag)
+	app.Post("/configure-mock/tags/:tag/disable", handlers.DisableMocksByTag)
 
 	// Rutas para la gestión de configuraciones de mocks
 	app.Post("/configure-mock", handlers.ConfigureMock)
 	app.Get("/configure-mock", handlers.GetMockConfigurations)
+	app.Get("/configure-mock/:id", handlers.GetMockConfiguration)
+	app.Put("/configure-mock/:id", handlers.ReplaceMockConfiguration)
+	app.Patch("/configure-mock/:id", handlers.PatchMockConfiguration)
 	app.Delete("/configure-mock/:id", handlers.DeleteMockConfiguration)
+	app.Post("/configure-mock/:id/enable", handlers.EnableMock)
+	app.Post("/configure-mock/:id/disable", handlers.DisableMock)
+	app.Get("/configure-mock/:id/history", handlers.GetMockHistory)
+	app.Post("/configure-mock/:id/rollback/:revision", handlers.RollbackMockConfiguration)
+
+	// Ruta para consultar el journal de solicitudes recibidas por los mocks
+	app.Get("/__admin/requests", handlers.GetJournal)
 
 	// Endpoint Genérico para la ejecución de mocks
 	app.All("/*", handlers.ExecuteMock)
 
-	// Iniciar el servidor Fiber
-	port := os.Getenv("PORT")
-
-	// Puerto por defecto si no se especifica
-	if port == "" {
-		port = "3000"
-	}
-
-	// Asegurarse de que el puerto tenga el prefijo correcto
-	if !strings.HasPrefix(port, ":") {
-		port = ":" + port
-	}
-
-	log.Printf("Servidor escuchando en el puerto %s", port)
-	if err := app.Listen(port); err != nil {
-		log.Fatalf("Error al iniciar el servidor: %v", err)
-	}
+	return app
 }
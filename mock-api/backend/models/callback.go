@@ -0,0 +1,16 @@
+package models
+
+// MockCallback describe una solicitud saliente (webhook) que el servidor envía de forma asíncrona
+// después de responder un mock, por ejemplo la confirmación de un proveedor de pagos.
+// La URL, los headers y el body se evalúan como plantillas con el motor del mock.
+type MockCallback struct {
+	URL          string            `json:"url"`
+	Method       string            `json:"method,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         interface{}       `json:"body,omitempty"`
+	DelayMs      int               `json:"delayMs,omitempty"`
+	Retries      int               `json:"retries,omitempty"`
+	RetryDelayMs int               `json:"retryDelayMs,omitempty"`
+	HmacSecret   string            `json:"hmacSecret,omitempty"`
+	HmacHeader   string            `json:"hmacHeader,omitempty"`
+}
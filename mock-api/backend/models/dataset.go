@@ -0,0 +1,8 @@
+package models
+
+// DatasetInfo resume un dataset cargado (tabla de datos consultable desde las plantillas con 'lookup').
+type DatasetInfo struct {
+	Name    string   `json:"name"`
+	Records int      `json:"records"`
+	Fields  []string `json:"fields"`
+}
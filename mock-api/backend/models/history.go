@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Acciones registradas en el historial de un mock.
+const (
+	HistoryActionCreate   = "create"
+	HistoryActionUpdate   = "update"
+	HistoryActionDelete   = "delete"
+	HistoryActionRollback = "rollback"
+	// HistoryActionBaseline marca la primera entrada de los mocks que ya existían antes de guardar historial.
+	HistoryActionBaseline = "baseline"
+)
+
+// MockHistoryEntry es una revisión guardada de una configuración de mock: quién la hizo, cuándo,
+// qué campos cambiaron respecto de la revisión anterior y la configuración resultante.
+// En las entradas de eliminación Config es nil.
+type MockHistoryEntry struct {
+	Revision   int               `json:"revision"`
+	Action     string            `json:"action"`
+	Author     string            `json:"author"`
+	Timestamp  time.Time         `json:"timestamp"`
+	RollbackOf int               `json:"rollbackOf,omitempty"`
+	Diff       []MockFieldChange `json:"diff,omitempty"`
+	Config     *MockConfig       `json:"config,omitempty"`
+}
+
+// MockFieldChange describe el cambio de un campo de la configuración entre dos revisiones.
+// Old es nil si el campo no existía y New es nil si el campo se eliminó.
+type MockFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
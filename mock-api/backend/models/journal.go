@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Quién respondió una solicitud registrada en el journal. Una solicitud sin respuesta configurada
+// (404) queda con JournalHandlerNone.
+const (
+	JournalHandlerMock      = "mock"
+	JournalHandlerResource  = "resource"
+	JournalHandlerProxy     = "proxy"
+	JournalHandlerRecording = "recording"
+	JournalHandlerNone      = "none"
+)
+
+// JournalEntry es una solicitud recibida por el endpoint de ejecución de mocks, con el mock que la
+// respondió (si hubo uno), el código de estado devuelto y el tiempo que tardó en responderse.
+type JournalEntry struct {
+	Id            string            `json:"id"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Method        string            `json:"method"`
+	Path          string            `json:"path"`
+	Query         map[string]string `json:"query,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Body          string            `json:"body,omitempty"`
+	BodyTruncated bool              `json:"bodyTruncated,omitempty"`
+	Matched       bool              `json:"matched"`
+	MockId        string            `json:"mockId,omitempty"`
+	HandledBy     string            `json:"handledBy"`
+	Status        int               `json:"status"`
+	LatencyMs     float64           `json:"latencyMs"`
+}
+
+// JournalFilter son los criterios para consultar el journal. Los campos vacíos no filtran.
+type JournalFilter struct {
+	Path       string
+	PathPrefix string
+	Method     string
+	MockId     string
+	From       time.Time
+	To         time.Time
+	Matched    *bool
+	Limit      int
+}
@@ -0,0 +1,65 @@
+package models
+
+// Criterios que se evalúan para decidir si un mock coincide con una solicitud, en el orden en que se evalúan.
+const (
+	MatchCriterionEnabled     = "enabled"
+	MatchCriterionPath        = "path"
+	MatchCriterionMethod      = "method"
+	MatchCriterionQueryParams = "queryParams"
+	MatchCriterionBodyParams  = "bodyParams"
+	MatchCriterionHeaders     = "headers"
+	MatchCriterionScenario    = "scenario"
+)
+
+// MatchMismatch describe un valor que no coincide dentro de un criterio, por ejemplo el header
+// 'Authorization' esperado y el recibido. Missing indica que la solicitud no trae el valor.
+type MatchMismatch struct {
+	Field    string      `json:"field"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual,omitempty"`
+	Missing  bool        `json:"missing,omitempty"`
+}
+
+// MatchCriterion es el resultado de evaluar un criterio de un mock contra una solicitud.
+type MatchCriterion struct {
+	Name       string          `json:"name"`
+	Passed     bool            `json:"passed"`
+	Mismatches []MatchMismatch `json:"mismatches,omitempty"`
+}
+
+// MockMatchResult es el resultado de evaluar un mock contra una solicitud.
+type MockMatchResult struct {
+	MockId     string            `json:"mockId"`
+	Path       string            `json:"path"`
+	Method     string            `json:"method"`
+	Priority   int               `json:"priority"`
+	Matched    bool              `json:"matched"`
+	Selected   bool              `json:"selected"`
+	PathParams map[string]string `json:"pathParams,omitempty"`
+	Criteria   []MatchCriterion  `json:"criteria"`
+}
+
+// ExplainRequest es la solicitud de ejemplo que recibe POST /configure-mock/explain. El path puede incluir
+// el query string; Body puede ser un objeto JSON o un texto.
+type ExplainRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   map[string]string `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// MatchDifference es un valor que no coincide entre un mock y una solicitud, junto con el criterio al que pertenece.
+type MatchDifference struct {
+	Criterion string `json:"criterion"`
+	MatchMismatch
+}
+
+// NearMiss es un mock que casi coincide con una solicitud que terminó sin respuesta, con lo que lo impidió.
+type NearMiss struct {
+	MockId      string            `json:"mockId"`
+	Path        string            `json:"path"`
+	Method      string            `json:"method"`
+	Priority    int               `json:"priority"`
+	Differences []MatchDifference `json:"differences"`
+}
@@ -2,18 +2,59 @@ package models
 
 // MockConfig representa la configuración de un mock.
 type MockConfig struct {
-	Id                 string                 `json:"id"`
-	Path               string                 `json:"path"`
-	Method             string                 `json:"method"`
-	QueryParams        map[string]string      `json:"queryParams"`
-	BodyParams         map[string]interface{} `json:"bodyParams"`
-	Headers            map[string]string      `json:"headers"`
-	ResponseStatusCode int                    `json:"responseStatusCode"`
-	ResponseBody       interface{}            `json:"responseBody"`
-	ContentType        string                 `json:"contentType"`
-	IsTemplate         bool                   `json:"isTemplate,omitempty"`
-	Priority           int                    `json:"priority,omitempty"`
+	Id                         string                 `json:"id"`
+	Revision                   int                    `json:"revision"`
+	Path                       string                 `json:"path"`
+	Method                     string                 `json:"method"`
+	QueryParams                map[string]string      `json:"queryParams"`
+	BodyParams                 map[string]interface{} `json:"bodyParams"`
+	Headers                    map[string]string      `json:"headers"`
+	ResponseStatusCode         int                    `json:"responseStatusCode"`
+	ResponseStatusCodeTemplate string                 `json:"responseStatusCodeTemplate,omitempty"`
+	ResponseHeaders            map[string]string      `json:"responseHeaders,omitempty"`
+	ResponseBody               interface{}            `json:"responseBody"`
+	ContentType                string                 `json:"contentType"`
+	IsTemplate                 bool                   `json:"isTemplate,omitempty"`
+	RawBody                    bool                   `json:"rawBody,omitempty"`
+	Base64Body                 bool                   `json:"base64Body,omitempty"`
+	Priority                   int                    `json:"priority,omitempty"`
+	Tags                       []string               `json:"tags,omitempty"`
+	Enabled                    *bool                  `json:"enabled,omitempty"`
+	TemplateVersion            int                    `json:"templateVersion,omitempty"`
+	TemplateEngine             string                 `json:"templateEngine,omitempty"`
+	ResponseMode               string                 `json:"responseMode,omitempty"`
+	Script                     string                 `json:"script,omitempty"`
+	Scenario                   string                 `json:"scenario,omitempty"`
+	RequiredScenarioState      string                 `json:"requiredScenarioState,omitempty"`
+	NewScenarioState           string                 `json:"newScenarioState,omitempty"`
+	Callbacks                  []MockCallback         `json:"callbacks,omitempty"`
+	ProxyTo                    string                 `json:"proxyTo,omitempty"`
+	ProxyTransform             *ProxyTransform        `json:"proxyTransform,omitempty"`
+}
+
+// IsEnabled indica si el mock participa en la coincidencia de solicitudes. Un valor ausente equivale a habilitado.
+func (m MockConfig) IsEnabled() bool {
+	return m.Enabled == nil || *m.Enabled
 }
 
 // Para facilitar la deserialización de parámetros del body, si es JSON
 type RequestBody map[string]interface{}
+
+// CurrentTemplateVersion es la versión vigente del contexto de datos expuesto a las plantillas.
+// Los mocks con una versión anterior se migran automáticamente al cargarse.
+const CurrentTemplateVersion = 1
+
+// Motores de plantillas soportados por TemplateEngine. Un valor vacío equivale a TemplateEngineGo.
+const (
+	TemplateEngineGo         = "go"
+	TemplateEngineHandlebars = "handlebars"
+)
+
+// Modos de respuesta soportados por ResponseMode. Un valor vacío equivale a ResponseModeStatic,
+// donde la respuesta se toma de ResponseBody (procesado como plantilla si IsTemplate es verdadero).
+// En ResponseModeProxy la solicitud se reenvía a ProxyTo y la respuesta se modifica con ProxyTransform.
+const (
+	ResponseModeStatic = "static"
+	ResponseModeScript = "script"
+	ResponseModeProxy  = "proxy"
+)
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// MockSetVersion es la versión del formato de los documentos de exportación de mocks.
+const MockSetVersion = 1
+
+// MockSet es un conjunto completo de mocks exportado o importado como un solo documento JSON o YAML.
+type MockSet struct {
+	Version    int          `json:"version"`
+	ExportedAt time.Time    `json:"exportedAt"`
+	Mocks      []MockConfig `json:"mocks"`
+}
+
+// Resultado de cada mock en una importación. Además de HistoryActionCreate y HistoryActionUpdate,
+// un mock puede quedar sin cambios o ser inválido.
+const (
+	ImportActionUnchanged = "unchanged"
+	ImportActionInvalid   = "invalid"
+)
+
+// MockImportResult describe qué ocurrió (o, en un dry-run, qué ocurriría) con un mock del documento importado.
+type MockImportResult struct {
+	Index    int         `json:"index"`
+	Id       string      `json:"id,omitempty"`
+	Action   string      `json:"action"`
+	Revision int         `json:"revision,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Details  interface{} `json:"details,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// TemplatePartial representa un fragmento de plantilla reutilizable (ej. un sobre de error estándar).
+// Engine indica su motor (TemplateEngineGo si está vacío): los partials Go se invocan con
+// '{{template "nombre" .}}' y los Handlebars con '{{> nombre}}', solo desde plantillas del mismo motor.
+type TemplatePartial struct {
+	Name        string `json:"name"`
+	Template    string `json:"template"`
+	Engine      string `json:"engine,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PartialEngine devuelve el motor del partial, TemplateEngineGo si no se indicó.
+func (p TemplatePartial) PartialEngine() string {
+	if p.Engine == "" {
+		return TemplateEngineGo
+	}
+	return p.Engine
+}
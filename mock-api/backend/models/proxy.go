@@ -0,0 +1,34 @@
+package models
+
+// ProxyConfig define a dónde se reenvían las solicitudes que no coinciden con ningún mock ni recurso.
+// Las rutas por prefijo tienen precedencia sobre el upstream global (DefaultUpstream).
+type ProxyConfig struct {
+	DefaultUpstream string       `json:"defaultUpstream,omitempty"`
+	Routes          []ProxyRoute `json:"routes,omitempty"`
+}
+
+// ProxyRoute reenvía las solicitudes cuya ruta comienza con PathPrefix al upstream indicado.
+// Con StripPrefix el prefijo se elimina de la ruta reenviada.
+type ProxyRoute struct {
+	PathPrefix  string `json:"pathPrefix"`
+	Upstream    string `json:"upstream"`
+	StripPrefix bool   `json:"stripPrefix,omitempty"`
+}
+
+// ProxyTransform describe los cambios que un mock con responseMode "proxy" aplica a la respuesta
+// del upstream antes de devolverla: código de estado, headers y body (JSON Patch y/o JSON Merge Patch).
+type ProxyTransform struct {
+	Status        int                  `json:"status,omitempty"`
+	SetHeaders    map[string]string    `json:"setHeaders,omitempty"`
+	RemoveHeaders []string             `json:"removeHeaders,omitempty"`
+	JSONPatch     []JSONPatchOperation `json:"jsonPatch,omitempty"`
+	MergePatch    interface{}          `json:"mergePatch,omitempty"`
+}
+
+// JSONPatchOperation es una operación de JSON Patch (RFC 6902).
+type JSONPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// RecordingConfig define una sesión de grabación: las solicitudes se reenvían a Target y cada
+// par solicitud/respuesta se guarda para convertirlo en mocks al detener la grabación.
+// Los campos Match* indican qué partes de la solicitud se convierten en criterios de coincidencia.
+type RecordingConfig struct {
+	Target           string   `json:"target"`
+	MatchQueryParams bool     `json:"matchQueryParams,omitempty"`
+	MatchHeaders     []string `json:"matchHeaders,omitempty"`
+	MatchBody        bool     `json:"matchBody,omitempty"`
+}
+
+// RecordedExchange es un par solicitud/respuesta capturado durante una grabación. Un body de respuesta
+// que no es texto UTF-8 se guarda en base64 (ResponseBodyBase64).
+type RecordedExchange struct {
+	Method             string            `json:"method"`
+	Path               string            `json:"path"`
+	QueryParams        map[string]string `json:"queryParams,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	Body               RequestBody       `json:"body,omitempty"`
+	Status             int               `json:"status"`
+	ResponseHeaders    map[string]string `json:"responseHeaders,omitempty"`
+	ResponseBody       string            `json:"responseBody"`
+	ResponseBodyBase64 bool              `json:"responseBodyBase64,omitempty"`
+	RecordedAt         time.Time         `json:"recordedAt"`
+}
+
+// RecordingStatus resume el estado de la grabación actual.
+type RecordingStatus struct {
+	Active    bool             `json:"active"`
+	Config    *RecordingConfig `json:"config,omitempty"`
+	StartedAt *time.Time       `json:"startedAt,omitempty"`
+	Recorded  int              `json:"recorded"`
+	Dropped   int              `json:"dropped,omitempty"`
+}
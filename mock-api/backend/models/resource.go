@@ -0,0 +1,10 @@
+package models
+
+// ResourceConfig declara un recurso REST que el servidor expone con CRUD completo en memoria,
+// por ejemplo '/api/v1/productos' con sus datos iniciales.
+type ResourceConfig struct {
+	Name    string                   `json:"name"`
+	Path    string                   `json:"path"`
+	IdField string                   `json:"idField,omitempty"`
+	Seed    []map[string]interface{} `json:"seed,omitempty"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// ScenarioStarted es el estado inicial de todo escenario.
+const ScenarioStarted = "Started"
+
+// ScenarioState representa el estado actual de un escenario y los estados que usan sus mocks.
+type ScenarioState struct {
+	Name           string   `json:"name"`
+	State          string   `json:"state"`
+	PossibleStates []string `json:"possibleStates"`
+}
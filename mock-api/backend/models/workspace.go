@@ -0,0 +1,7 @@
+package models
+
+// WorkspaceInfo resume un workspace: un conjunto aislado de mocks, escenarios e historial.
+type WorkspaceInfo struct {
+	Name  string `json:"name"`
+	Mocks int    `json:"mocks"`
+}
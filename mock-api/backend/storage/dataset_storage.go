@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"backend/models"
+)
+
+// Carpeta donde se guarda cada dataset como un archivo JSON con la lista de registros.
+const datasetsDirName = "config/datasets"
+
+// dataset mantiene los registros de un dataset y los índices por campo, que se construyen
+// la primera vez que se consulta cada campo.
+type dataset struct {
+	records []map[string]interface{}
+	indexes map[string]map[string][]int
+}
+
+// Variables globales para los datasets cargados
+var (
+	datasets     = make(map[string]*dataset)
+	datasetMutex sync.RWMutex
+)
+
+// InitDatasetStorage carga los datasets guardados en la carpeta de datasets.
+func InitDatasetStorage() {
+	datasetMutex.Lock()
+	defer datasetMutex.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(datasetsDirName, "*.json"))
+	if err != nil || len(files) == 0 {
+		log.Printf("No se encontraron datasets en '%s'. Iniciando sin datasets.", datasetsDirName)
+		return
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Error al leer el dataset '%s': %v", file, err)
+			continue
+		}
+		var records []map[string]interface{}
+		if err := json.Unmarshal(data, &records); err != nil {
+			log.Printf("Error al deserializar el dataset '%s': %v", file, err)
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		datasets[name] = &dataset{records: records, indexes: make(map[string]map[string][]int)}
+	}
+	log.Printf("Datasets cargados exitosamente desde '%s'. Total: %d", datasetsDirName, len(datasets))
+}
+
+// datasetFileName devuelve la ruta del archivo de un dataset.
+func datasetFileName(name string) string {
+	return filepath.Join(datasetsDirName, name+".json")
+}
+
+// SaveDataset crea o reemplaza un dataset y lo guarda en disco.
+func SaveDataset(name string, records []map[string]interface{}) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		log.Printf("Error al serializar el dataset '%s' a JSON: %v", name, err)
+		return err
+	}
+
+	datasetMutex.Lock()
+	defer datasetMutex.Unlock()
+
+	if err := os.MkdirAll(datasetsDirName, 0755); err != nil {
+		log.Printf("Error al crear la carpeta de datasets '%s': %v", datasetsDirName, err)
+		return err
+	}
+	if err := os.WriteFile(datasetFileName(name), data, 0644); err != nil {
+		log.Printf("Error al escribir el dataset en el archivo '%s': %v", datasetFileName(name), err)
+		return err
+	}
+	datasets[name] = &dataset{records: records, indexes: make(map[string]map[string][]int)}
+	log.Printf("Dataset '%s' guardado exitosamente. Registros: %d", name, len(records))
+	return nil
+}
+
+// GetDataset obtiene una copia de todos los registros de un dataset.
+func GetDataset(name string) ([]map[string]interface{}, bool) {
+	datasetMutex.RLock()
+	defer datasetMutex.RUnlock()
+	ds, ok := datasets[name]
+	if !ok {
+		return nil, false
+	}
+	records := make([]map[string]interface{}, len(ds.records))
+	for i, record := range ds.records {
+		records[i] = cloneRecord(record)
+	}
+	return records, true
+}
+
+// GetAllDatasets obtiene el resumen de todos los datasets, ordenados por nombre.
+func GetAllDatasets() []models.DatasetInfo {
+	datasetMutex.RLock()
+	defer datasetMutex.RUnlock()
+
+	infos := make([]models.DatasetInfo, 0, len(datasets))
+	for name, ds := range datasets {
+		fields := make(map[string]bool)
+		for _, record := range ds.records {
+			for field := range record {
+				fields[field] = true
+			}
+		}
+		list := make([]string, 0, len(fields))
+		for field := range fields {
+			list = append(list, field)
+		}
+		sort.Strings(list)
+		infos = append(infos, models.DatasetInfo{Name: name, Records: len(ds.records), Fields: list})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// DeleteDataset elimina un dataset de memoria y de disco. Devuelve false si no existía.
+func DeleteDataset(name string) bool {
+	datasetMutex.Lock()
+	defer datasetMutex.Unlock()
+	if _, ok := datasets[name]; !ok {
+		return false
+	}
+	delete(datasets, name)
+	if err := os.Remove(datasetFileName(name)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error al eliminar el archivo del dataset '%s': %v", datasetFileName(name), err)
+	}
+	return true
+}
+
+// LookupDataset obtiene una copia de los registros cuyo campo coincide con el valor indicado.
+// Los valores se comparan como texto, por lo que el ID 7 de un JSON coincide con el parámetro de ruta "7".
+func LookupDataset(name, field, value string) ([]map[string]interface{}, bool) {
+	datasetMutex.RLock()
+	ds, ok := datasets[name]
+	if !ok {
+		datasetMutex.RUnlock()
+		return nil, false
+	}
+	index, indexed := ds.indexes[field]
+	datasetMutex.RUnlock()
+
+	if !indexed {
+		datasetMutex.Lock()
+		// Otro goroutine pudo haber reemplazado el dataset o construido el índice mientras tanto
+		if ds, ok = datasets[name]; !ok {
+			datasetMutex.Unlock()
+			return nil, false
+		}
+		if index, indexed = ds.indexes[field]; !indexed {
+			index = make(map[string][]int)
+			for i, record := range ds.records {
+				if v, exists := record[field]; exists {
+					key := datasetKey(v)
+					index[key] = append(index[key], i)
+				}
+			}
+			ds.indexes[field] = index
+		}
+		datasetMutex.Unlock()
+	}
+
+	datasetMutex.RLock()
+	defer datasetMutex.RUnlock()
+	matches := make([]map[string]interface{}, 0, len(index[value]))
+	for _, i := range index[value] {
+		matches = append(matches, cloneRecord(ds.records[i]))
+	}
+	return matches, true
+}
+
+// datasetKey convierte el valor de un campo a la clave de texto usada en los índices.
+func datasetKey(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+}
+
+// cloneRecord copia un registro para que las plantillas y scripts no modifiquen los datos cargados.
+func cloneRecord(record map[string]interface{}) map[string]interface{} {
+	clone, err := cloneStoreValue(record)
+	if err != nil {
+		return record
+	}
+	copied, _ := clone.(map[string]interface{})
+	return copied
+}
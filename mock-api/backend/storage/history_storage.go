@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"backend/models"
+)
+
+// Constante con el nombre del archivo donde se guarda el historial de revisiones de los mocks
+const historyFileName = "config/history.json"
+
+// defaultHistoryLimit es la cantidad máxima de revisiones que se guardan por mock; al superarla se descartan
+// las más antiguas. Se puede ajustar con la variable de entorno HISTORY_LIMIT.
+const defaultHistoryLimit = 100
+
+// ErrRevisionNotFound indica que la revisión pedida no existe en el historial del mock o no tiene configuración
+// (por ejemplo, una eliminación).
+var ErrRevisionNotFound = errors.New("revisión de mock no encontrada en el historial")
+
+// El historial de cada workspace (mockWorkspace.history) guarda las revisiones de cada mock por ID, en orden
+// ascendente. Se protege con el mismo mutex que las configuraciones para que cada cambio y su entrada de
+// historial se registren juntos.
+
+// historyLimit devuelve la cantidad máxima de revisiones que se guardan por mock.
+func historyLimit() int {
+	if v, err := strconv.Atoi(os.Getenv("HISTORY_LIMIT")); err == nil && v > 0 {
+		return v
+	}
+	return defaultHistoryLimit
+}
+
+// pruneHistory descarta las revisiones más antiguas del mock que superan el límite del historial.
+// Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) pruneHistory(id string) {
+	entries := ws.history[id]
+	if excess := len(entries) - historyLimit(); excess > 0 {
+		// Se copian las entradas conservadas para liberar el arreglo anterior
+		ws.history[id] = append([]models.MockHistoryEntry(nil), entries[excess:]...)
+	}
+}
+
+// loadHistory carga el historial del workspace desde su archivo. Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) loadHistory() {
+	data, err := os.ReadFile(ws.historyFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error al leer el archivo de historial '%s': %v", ws.historyFile, err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &ws.history); err != nil {
+		log.Printf("Error al deserializar el historial desde '%s': %v. Iniciando con historial vacío.", ws.historyFile, err)
+		ws.history = make(map[string][]models.MockHistoryEntry)
+		return
+	}
+	// Un archivo guardado con un límite mayor se recorta al límite actual
+	for id := range ws.history {
+		ws.pruneHistory(id)
+	}
+	log.Printf("Historial de mocks cargado exitosamente desde '%s'. Mocks: %d", ws.historyFile, len(ws.history))
+}
+
+// saveHistory guarda el historial del workspace en su archivo JSON. Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) saveHistory() {
+	if err := writeWorkspaceFile(ws.historyFile, ws.history); err != nil {
+		log.Printf("Error al escribir el historial en el archivo '%s': %v", ws.historyFile, err)
+	}
+}
+
+// seedHistoryBaseline agrega una entrada inicial para los mocks cargados que aún no tienen historial,
+// de modo que su configuración actual pueda restaurarse. Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) seedHistoryBaseline() {
+	seeded := 0
+	for id, config := range ws.configs {
+		if len(ws.history[id]) > 0 {
+			continue
+		}
+		snapshot := config
+		ws.history[id] = []models.MockHistoryEntry{{
+			Revision:  config.Revision,
+			Action:    models.HistoryActionBaseline,
+			Author:    "system",
+			Timestamp: time.Now(),
+			Config:    &snapshot,
+		}}
+		seeded++
+	}
+	if seeded > 0 {
+		log.Printf("Historial inicial creado para %d mocks existentes en '%s'", seeded, ws.historyFile)
+		ws.saveHistory()
+	}
+}
+
+// nextRevision devuelve la revisión que corresponde al próximo cambio del mock. Considera el historial
+// para que un mock eliminado y vuelto a crear no repita revisiones. Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) nextRevision(id string) int {
+	revision := ws.configs[id].Revision
+	if entries := ws.history[id]; len(entries) > 0 && entries[len(entries)-1].Revision > revision {
+		revision = entries[len(entries)-1].Revision
+	}
+	return revision + 1
+}
+
+// recordRevision agrega una entrada al historial del mock y guarda el historial en el archivo.
+// Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) recordRevision(id string, revision int, action, author string, config *models.MockConfig, rollbackOf int) {
+	ws.appendRevision(id, revision, action, author, config, rollbackOf)
+	ws.saveHistory()
+}
+
+// appendRevision agrega una entrada al historial del mock con el cambio respecto de la revisión anterior,
+// sin guardar el archivo, y descarta las más antiguas que superan el límite. config es nil en las
+// eliminaciones. Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) appendRevision(id string, revision int, action, author string, config *models.MockConfig, rollbackOf int) {
+	var previous *models.MockConfig
+	if entries := ws.history[id]; len(entries) > 0 {
+		previous = entries[len(entries)-1].Config
+	}
+
+	var snapshot *models.MockConfig
+	if config != nil {
+		copied := *config
+		snapshot = &copied
+	}
+
+	ws.history[id] = append(ws.history[id], models.MockHistoryEntry{
+		Revision:   revision,
+		Action:     action,
+		Author:     author,
+		Timestamp:  time.Now(),
+		RollbackOf: rollbackOf,
+		Diff:       diffMockConfigs(previous, snapshot),
+		Config:     snapshot,
+	})
+	ws.pruneHistory(id)
+}
+
+// mockFields devuelve los campos de una configuración tal como se serializan a JSON, sin la revisión.
+func mockFields(config *models.MockConfig) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if config == nil {
+		return fields
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	delete(fields, "revision")
+	return fields
+}
+
+// diffMockConfigs compara dos configuraciones campo a campo y devuelve los cambios ordenados por nombre de campo.
+func diffMockConfigs(previous, current *models.MockConfig) []models.MockFieldChange {
+	oldFields, newFields := mockFields(previous), mockFields(current)
+
+	names := make(map[string]bool, len(oldFields)+len(newFields))
+	for name := range oldFields {
+		names[name] = true
+	}
+	for name := range newFields {
+		names[name] = true
+	}
+
+	var changes []models.MockFieldChange
+	for name := range names {
+		if !reflect.DeepEqual(oldFields[name], newFields[name]) {
+			changes = append(changes, models.MockFieldChange{Field: name, Old: oldFields[name], New: newFields[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// GetMockHistory obtiene las revisiones de un mock del workspace, de la más reciente a la más antigua.
+// Incluye las de mocks eliminados, para poder restaurarlos.
+func GetMockHistory(workspace, id string) ([]models.MockHistoryEntry, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	ws := getWorkspace(workspace)
+	if ws == nil {
+		return nil, false
+	}
+	entries, ok := ws.history[id]
+	if !ok {
+		return nil, false
+	}
+	history := make([]models.MockHistoryEntry, len(entries))
+	for i, entry := range entries {
+		history[len(entries)-1-i] = entry
+	}
+	return history, true
+}
+
+// RollbackMockConfig restaura la configuración que tenía un mock en la revisión indicada. La restauración
+// se guarda como una revisión nueva, por lo que también puede deshacerse. expectedRevision es la revisión
+// actual esperada (0 si el mock fue eliminado).
+// Devuelve ErrMockNotFound si el mock no tiene historial, ErrRevisionNotFound si la revisión no existe
+// o no tiene configuración y ErrMockConflict si la revisión actual cambió.
+func RollbackMockConfig(workspace, id string, revision, expectedRevision int, author string) (models.MockConfig, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ws := ensureWorkspace(workspace)
+
+	entries, ok := ws.history[id]
+	if !ok {
+		return models.MockConfig{}, ErrMockNotFound
+	}
+	var target *models.MockConfig
+	for _, entry := range entries {
+		if entry.Revision == revision {
+			target = entry.Config
+			break
+		}
+	}
+	if target == nil {
+		return models.MockConfig{}, ErrRevisionNotFound
+	}
+	if ws.configs[id].Revision != expectedRevision {
+		return models.MockConfig{}, ErrMockConflict
+	}
+
+	config := *target
+	config.Revision = ws.nextRevision(id)
+	ws.configs[id] = config
+	if err := ws.save(); err != nil {
+		return config, err
+	}
+	ws.recordRevision(id, config.Revision, models.HistoryActionRollback, author, &config, revision)
+	return config, nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"backend/models"
+)
+
+// historyRevisions devuelve las revisiones guardadas del mock, de la más reciente a la más antigua.
+func historyRevisions(id string) string {
+	entries, _ := GetMockHistory(DefaultWorkspace, id)
+	revisions := make([]string, len(entries))
+	for i, entry := range entries {
+		revisions[i] = strconv.Itoa(entry.Revision)
+	}
+	return strings.Join(revisions, ",")
+}
+
+func TestHistoryLimit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HISTORY_LIMIT", "3")
+	InitMockStorage()
+
+	for i := 0; i < 5; i++ {
+		config := models.MockConfig{Id: "m1", Path: "/items", Method: "GET", ResponseStatusCode: 200 + i}
+		if err := AddMockConfig(DefaultWorkspace, config, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := historyRevisions("m1"), "5,4,3"; got != want {
+		t.Errorf("revisiones = %s, se esperaba %s", got, want)
+	}
+
+	// Una revisión descartada ya no puede restaurarse
+	if _, err := RollbackMockConfig(DefaultWorkspace, "m1", 1, 5, "test"); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("rollback a una revisión descartada: se obtuvo %v", err)
+	}
+	if _, err := RollbackMockConfig(DefaultWorkspace, "m1", 3, 5, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := historyRevisions("m1"), "6,5,4"; got != want {
+		t.Errorf("revisiones después del rollback = %s, se esperaba %s", got, want)
+	}
+
+	// Al cargar el archivo con un límite menor se conservan las más recientes
+	t.Setenv("HISTORY_LIMIT", "2")
+	InitMockStorage()
+	if got, want := historyRevisions("m1"), "6,5"; got != want {
+		t.Errorf("revisiones cargadas = %s, se esperaba %s", got, want)
+	}
+}
@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"backend/models"
+)
+
+// defaultJournalSize es la cantidad de solicitudes que guarda el journal de cada workspace.
+// Se puede ajustar con la variable de entorno JOURNAL_SIZE.
+const defaultJournalSize = 1000
+
+// Archivo donde se persiste el journal del workspace por defecto (con JOURNAL_PERSIST=true).
+// Los demás workspaces usan config/workspaces/{nombre}/journal.jsonl.
+const journalFileName = "config/journal.jsonl"
+
+// journalQueueSize es la cantidad de solicitudes que pueden esperar a escribirse en disco. Si el escritor
+// no alcanza a vaciar la cola, las solicitudes siguientes quedan en memoria pero no se persisten.
+const journalQueueSize = 4096
+
+// requestJournal guarda las últimas solicitudes de un workspace en un buffer circular. Si la persistencia
+// está activa, cada solicitud se agrega además como una línea JSON al archivo del workspace.
+type requestJournal struct {
+	entries []models.JournalEntry
+	next    int // Posición donde se guarda la próxima solicitud
+	count   int
+	added   int // Solicitudes agregadas desde que se creó el journal; numera las escrituras pendientes
+	file    string
+
+	// Campos usados solo por el escritor del journal (y por load antes de publicarse el journal)
+	persisted int // Líneas del archivo; al superar el doble del tamaño se reescribe solo con el buffer
+	compacted int // Número de la última solicitud incluida en la última reescritura del archivo
+}
+
+// journalWrite es una solicitud pendiente de escribirse en el archivo de su journal.
+type journalWrite struct {
+	journal *requestJournal
+	entry   models.JournalEntry
+	seq     int
+}
+
+// Journals por workspace. Se crean con la primera solicitud o consulta de un workspace existente.
+var (
+	journals       = make(map[string]*requestJournal)
+	journalMutex   sync.Mutex
+	journalSize    = defaultJournalSize
+	journalPersist bool
+	journalWrites  chan journalWrite
+	journalPending sync.WaitGroup
+)
+
+// InitJournalStorage lee la configuración del journal: JOURNAL_SIZE (solicitudes por workspace) y
+// JOURNAL_PERSIST (guardar las solicitudes en disco para conservarlas al reiniciar).
+func InitJournalStorage() {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	if v, err := strconv.Atoi(os.Getenv("JOURNAL_SIZE")); err == nil && v > 0 {
+		journalSize = v
+	}
+	journalPersist, _ = strconv.ParseBool(os.Getenv("JOURNAL_PERSIST"))
+	if journalPersist && journalWrites == nil {
+		journalWrites = make(chan journalWrite, journalQueueSize)
+		go writeJournal(journalWrites)
+		log.Printf("Journal de solicitudes persistente activado. Tamaño por workspace: %d", journalSize)
+	}
+}
+
+// writeJournal escribe en disco las solicitudes de la cola, fuera del mutex del journal. Las solicitudes que
+// llegan juntas se agrupan y se escriben con una sola operación por archivo.
+func writeJournal(writes <-chan journalWrite) {
+	for write := range writes {
+		batch := []journalWrite{write}
+	drain:
+		for len(batch) < journalQueueSize {
+			select {
+			case next := <-writes:
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+
+		byJournal := make(map[*requestJournal][]journalWrite)
+		var order []*requestJournal
+		for _, w := range batch {
+			if _, ok := byJournal[w.journal]; !ok {
+				order = append(order, w.journal)
+			}
+			byJournal[w.journal] = append(byJournal[w.journal], w)
+		}
+		for _, journal := range order {
+			if err := journal.persist(byJournal[journal]); err != nil {
+				log.Printf("Error al guardar solicitudes en el journal '%s': %v", journal.file, err)
+			}
+		}
+		for range batch {
+			journalPending.Done()
+		}
+	}
+}
+
+// waitJournalWrites espera a que se escriban en disco las solicitudes pendientes.
+func waitJournalWrites() {
+	journalPending.Wait()
+}
+
+// workspaceJournal devuelve el journal del workspace y lo crea si no existe, cargando las solicitudes
+// guardadas si la persistencia está activa. Devuelve nil si el workspace no existe, para no reservar un
+// buffer por cada nombre de workspace que envíe un cliente. Debe llamarse con journalMutex tomado.
+func workspaceJournal(workspace string) *requestJournal {
+	journal, ok := journals[workspace]
+	if ok {
+		return journal
+	}
+	if !WorkspaceExists(workspace) {
+		return nil
+	}
+	journal = &requestJournal{entries: make([]models.JournalEntry, journalSize), file: journalFileName}
+	if workspace != DefaultWorkspace {
+		journal.file = filepath.Join(workspacesDir, workspace, "journal.jsonl")
+	}
+	if journalPersist {
+		journal.load()
+	}
+	journals[workspace] = journal
+	return journal
+}
+
+// load carga las solicitudes guardadas en el archivo del journal. Si hay más que el tamaño del buffer
+// se conservan las más recientes.
+func (j *requestJournal) load() {
+	data, err := os.ReadFile(j.file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error al leer el journal '%s': %v", j.file, err)
+		}
+		return
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		j.persisted++
+		var entry models.JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			log.Printf("Advertencia: se ignora una línea inválida del journal '%s': %v", j.file, err)
+			continue
+		}
+		j.add(entry)
+	}
+	log.Printf("Journal cargado desde '%s'. Total: %d", j.file, j.count)
+}
+
+// add guarda una solicitud en el buffer, reemplazando la más antigua si está lleno, y devuelve su número.
+func (j *requestJournal) add(entry models.JournalEntry) int {
+	j.entries[j.next] = entry
+	j.next = (j.next + 1) % len(j.entries)
+	if j.count < len(j.entries) {
+		j.count++
+	}
+	j.added++
+	return j.added
+}
+
+// newestFirst devuelve las solicitudes del buffer de la más reciente a la más antigua.
+func (j *requestJournal) newestFirst() []models.JournalEntry {
+	entries := make([]models.JournalEntry, 0, j.count)
+	for i := 1; i <= j.count; i++ {
+		entries = append(entries, j.entries[(j.next-i+len(j.entries))%len(j.entries)])
+	}
+	return entries
+}
+
+// persist agrega las solicitudes al archivo del journal. Cuando el archivo llega al doble del tamaño del
+// buffer se reescribe solo con las solicitudes del buffer, para que no crezca sin límite; las escrituras
+// pendientes que ya quedaron incluidas en esa reescritura se omiten.
+func (j *requestJournal) persist(writes []journalWrite) error {
+	if err := os.MkdirAll(filepath.Dir(j.file), 0755); err != nil {
+		return err
+	}
+	if j.persisted >= 2*len(j.entries) {
+		journalMutex.Lock()
+		entries, compacted := j.newestFirst(), j.added
+		journalMutex.Unlock()
+
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		for i := len(entries) - 1; i >= 0; i-- {
+			if err := encoder.Encode(entries[i]); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(j.file, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		j.persisted = len(entries)
+		j.compacted = compacted
+	}
+
+	var buf bytes.Buffer
+	lines := 0
+	for _, write := range writes {
+		if write.seq <= j.compacted {
+			continue
+		}
+		line, err := json.Marshal(write.entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+		lines++
+	}
+	if lines == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(j.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	j.persisted += lines
+	return nil
+}
+
+// AddJournalEntry registra una solicitud en el journal del workspace. Las solicitudes de un workspace que no
+// existe no se registran. Si la persistencia está activa, la solicitud se encola para que el escritor del
+// journal la guarde en disco; los errores al guardarla se registran en el log pero no afectan la respuesta.
+func AddJournalEntry(workspace string, entry models.JournalEntry) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	journal := workspaceJournal(workspace)
+	if journal == nil {
+		return
+	}
+	seq := journal.add(entry)
+	if journalWrites == nil {
+		return
+	}
+	journalPending.Add(1)
+	select {
+	case journalWrites <- journalWrite{journal: journal, entry: entry, seq: seq}:
+	default:
+		journalPending.Done()
+		log.Printf("Advertencia: la cola de escritura del journal está llena; la solicitud %s no se guardó en '%s'", entry.Id, journal.file)
+	}
+}
+
+// QueryJournal obtiene las solicitudes del journal del workspace que cumplen el filtro, de la más reciente
+// a la más antigua y hasta filter.Limit (0 sin límite), junto con el total que cumple el filtro.
+func QueryJournal(workspace string, filter models.JournalFilter) ([]models.JournalEntry, int) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	entries := []models.JournalEntry{}
+	total := 0
+	journal := workspaceJournal(workspace)
+	if journal == nil {
+		return entries, total
+	}
+	for _, entry := range journal.newestFirst() {
+		if !journalEntryMatches(entry, filter) {
+			continue
+		}
+		total++
+		if filter.Limit == 0 || len(entries) < filter.Limit {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, total
+}
+
+// JournalCapacity devuelve la cantidad máxima de solicitudes que guarda el journal de cada workspace.
+func JournalCapacity() int {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+	return journalSize
+}
+
+// journalEntryMatches verifica si una solicitud del journal cumple todos los criterios del filtro.
+func journalEntryMatches(entry models.JournalEntry, filter models.JournalFilter) bool {
+	switch {
+	case filter.Path != "" && entry.Path != filter.Path:
+		return false
+	case filter.PathPrefix != "" && !strings.HasPrefix(entry.Path, filter.PathPrefix):
+		return false
+	case filter.Method != "" && !strings.EqualFold(entry.Method, filter.Method):
+		return false
+	case filter.MockId != "" && entry.MockId != filter.MockId:
+		return false
+	case !filter.From.IsZero() && entry.Timestamp.Before(filter.From):
+		return false
+	case !filter.To.IsZero() && entry.Timestamp.After(filter.To):
+		return false
+	case filter.Matched != nil && entry.Matched != *filter.Matched:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/models"
+)
+
+// resetJournal deja el journal vacío, con el tamaño y la persistencia indicados, en un directorio temporal.
+// Con persistencia arranca un escritor del journal que se detiene al terminar la prueba.
+func resetJournal(t *testing.T, size int, persist bool) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("config", 0755); err != nil {
+		t.Fatal(err)
+	}
+	journalMutex.Lock()
+	journals = make(map[string]*requestJournal)
+	journalSize, journalPersist = size, persist
+	if persist {
+		journalWrites = make(chan journalWrite, journalQueueSize)
+		go writeJournal(journalWrites)
+	}
+	journalMutex.Unlock()
+
+	t.Cleanup(func() {
+		waitJournalWrites()
+		journalMutex.Lock()
+		defer journalMutex.Unlock()
+		if journalWrites != nil {
+			close(journalWrites)
+		}
+		journals = make(map[string]*requestJournal)
+		journalSize, journalPersist, journalWrites = defaultJournalSize, false, nil
+	})
+}
+
+// registerJournalWorkspace crea un workspace con puerto dedicado y lo elimina al terminar la prueba.
+func registerJournalWorkspace(t *testing.T, name string) {
+	t.Helper()
+	RegisterWorkspace(name)
+	t.Cleanup(func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		delete(workspaces, name)
+	})
+}
+
+// addJournalIds registra en el workspace una solicitud GET /items por cada ID, esperando a que cada una
+// se escriba en disco para que la reescritura del archivo ocurra siempre en el mismo punto.
+func addJournalIds(workspace string, ids ...string) {
+	for _, id := range ids {
+		AddJournalEntry(workspace, models.JournalEntry{Id: id, Method: "GET", Path: "/items"})
+		waitJournalWrites()
+	}
+}
+
+// journalIds devuelve los IDs del journal del workspace, de la solicitud más reciente a la más antigua.
+func journalIds(workspace string, filter models.JournalFilter) string {
+	entries, total := QueryJournal(workspace, filter)
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.Id
+	}
+	return strings.Join(ids, ",") + " total=" + strconv.Itoa(total)
+}
+
+func TestJournalEntryMatches(t *testing.T) {
+	at := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	entry := models.JournalEntry{Id: "1", Timestamp: at, Method: "POST", Path: "/orders/7", Matched: true, MockId: "m1"}
+	yes, no := true, false
+
+	tests := []struct {
+		filter models.JournalFilter
+		want   bool
+	}{
+		{models.JournalFilter{}, true},
+		{models.JournalFilter{Path: "/orders/7"}, true},
+		{models.JournalFilter{Path: "/orders"}, false},
+		{models.JournalFilter{PathPrefix: "/orders/"}, true},
+		{models.JournalFilter{PathPrefix: "/users"}, false},
+		{models.JournalFilter{Method: "post"}, true},
+		{models.JournalFilter{Method: "GET"}, false},
+		{models.JournalFilter{MockId: "m1"}, true},
+		{models.JournalFilter{MockId: "m2"}, false},
+		{models.JournalFilter{From: at, To: at}, true},
+		{models.JournalFilter{From: at.Add(time.Second)}, false},
+		{models.JournalFilter{To: at.Add(-time.Second)}, false},
+		{models.JournalFilter{Matched: &yes}, true},
+		{models.JournalFilter{Matched: &no}, false},
+		{models.JournalFilter{PathPrefix: "/orders", Method: "POST", MockId: "m1", Matched: &yes}, true},
+	}
+	for _, tt := range tests {
+		if got := journalEntryMatches(entry, tt.filter); got != tt.want {
+			t.Errorf("filtro %+v: se obtuvo %v, se esperaba %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestJournalKeepsNewestEntries(t *testing.T) {
+	resetJournal(t, 3, false)
+	registerJournalWorkspace(t, "qa")
+	addJournalIds(DefaultWorkspace, "1", "2", "3", "4", "5")
+	AddJournalEntry(DefaultWorkspace, models.JournalEntry{Id: "6", Method: "POST", Path: "/items", Matched: true})
+	addJournalIds("qa", "qa1")
+	addJournalIds("desconocido", "x1")
+
+	if got, want := journalIds(DefaultWorkspace, models.JournalFilter{}), "6,5,4 total=3"; got != want {
+		t.Errorf("journal por defecto: %s, se esperaba %s", got, want)
+	}
+	if got, want := journalIds(DefaultWorkspace, models.JournalFilter{Limit: 1}), "6 total=3"; got != want {
+		t.Errorf("con límite: %s, se esperaba %s", got, want)
+	}
+	if got, want := journalIds(DefaultWorkspace, models.JournalFilter{Method: "GET"}), "5,4 total=2"; got != want {
+		t.Errorf("con filtro: %s, se esperaba %s", got, want)
+	}
+	if got, want := journalIds("qa", models.JournalFilter{}), "qa1 total=1"; got != want {
+		t.Errorf("workspace qa: %s, se esperaba %s", got, want)
+	}
+
+	// Las solicitudes de un workspace inexistente no reservan un journal
+	if got, want := journalIds("desconocido", models.JournalFilter{}), " total=0"; got != want {
+		t.Errorf("workspace inexistente: %s, se esperaba %s", got, want)
+	}
+	if _, ok := journals["desconocido"]; ok {
+		t.Error("no debería crearse el journal de un workspace inexistente")
+	}
+}
+
+func TestJournalPersistence(t *testing.T) {
+	resetJournal(t, 3, true)
+	registerJournalWorkspace(t, "qa")
+	for i := 1; i <= 10; i++ {
+		addJournalIds("qa", strconv.Itoa(i))
+	}
+
+	// El archivo se reescribe solo con el buffer al llegar al doble de su tamaño
+	data, err := os.ReadFile("config/workspaces/qa/journal.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines > 6 {
+		t.Errorf("el archivo tiene %d líneas, se esperaban como máximo 6", lines)
+	}
+
+	// Al reiniciar se cargan las solicitudes más recientes del archivo
+	journalMutex.Lock()
+	journals = make(map[string]*requestJournal)
+	journalMutex.Unlock()
+	if got, want := journalIds("qa", models.JournalFilter{}), "10,9,8 total=3"; got != want {
+		t.Errorf("journal cargado: %s, se esperaba %s", got, want)
+	}
+}
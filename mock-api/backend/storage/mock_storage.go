@@ -2,8 +2,10 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"os"
+	"slices"
 	"sort"
 	"sync"
 
@@ -13,109 +15,339 @@ import (
 // Constante con el nmobre del archivo de almacenamiento de mocks
 const mocksFileName = "config/mocks.json"
 
-// Variables globales para almacenar las configuraciones de mocks
+// Errores devueltos al crear o actualizar configuraciones de mocks
 var (
-	mockConfigurations = make(map[string]models.MockConfig)
-	mutex              sync.RWMutex
+	ErrMockExists   = errors.New("ya existe un mock con el mismo ID")
+	ErrMockNotFound = errors.New("configuración de mock no encontrada")
+	ErrMockConflict = errors.New("la configuración de mock fue modificada por otra solicitud")
 )
 
-// InitMockStorage inicializa el almacenamiento y carga las configuraciones existentes desde el archivo.
+// Mutex global del almacenamiento de mocks. Protege todos los workspaces con sus mocks e historial.
+var mutex sync.RWMutex
+
+// InitMockStorage inicializa el almacenamiento y carga las configuraciones existentes desde los archivos:
+// el workspace por defecto desde config/mocks.json y los demás desde config/workspaces.
 func InitMockStorage() {
 	mutex.Lock()
 	defer mutex.Unlock()
 
+	ws := newMockWorkspace(DefaultWorkspace)
+	ws.load()
+	workspaces[DefaultWorkspace] = ws
+	loadWorkspaces()
+}
+
+// load carga los mocks y el historial del workspace desde sus archivos.
+func (ws *mockWorkspace) load() {
+	// Cargar el historial de revisiones. Al terminar la carga se registra la configuración actual
+	// de los mocks que todavía no tienen historial.
+	ws.loadHistory()
+	defer ws.seedHistoryBaseline()
+
 	// Intenta leer el archivo de mocks
-	data, err := os.ReadFile(mocksFileName)
+	data, err := os.ReadFile(ws.mocksFile)
 	if err != nil {
 		if os.IsNotExist(err) {
-			log.Printf("Archivo de mocks '%s' no encontrado. Iniciando con almacenamiento vacío.", mocksFileName)
+			log.Printf("Archivo de mocks '%s' no encontrado. Iniciando con almacenamiento vacío.", ws.mocksFile)
 			return // El archivo no existe, no hay nada que cargar
 		}
-		log.Printf("Error al leer el archivo de mocks '%s': %v", mocksFileName, err)
+		log.Printf("Error al leer el archivo de mocks '%s': %v", ws.mocksFile, err)
 		return // Otro error de lectura
 	}
 
 	// Si el archivo existe y se leyó, intenta deserializar el JSON
-	if err := json.Unmarshal(data, &mockConfigurations); err != nil {
+	if err := json.Unmarshal(data, &ws.configs); err != nil {
 
 		// Si hay un error de deserialización, loguea el error y comienza con un almacenamiento vacío
-		log.Printf("Error al deserializar mocks desde '%s': %v. Iniciando con almacenamiento vacío.", mocksFileName, err)
-		mockConfigurations = make(map[string]models.MockConfig)
+		log.Printf("Error al deserializar mocks desde '%s': %v. Iniciando con almacenamiento vacío.", ws.mocksFile, err)
+		ws.configs = make(map[string]models.MockConfig)
 	} else {
-		log.Printf("Mocks cargados exitosamente desde '%s'. Total: %d", mocksFileName, len(mockConfigurations))
+		log.Printf("Mocks cargados exitosamente desde '%s'. Total: %d", ws.mocksFile, len(ws.configs))
 	}
-}
 
-// saveMocksToFile guarda las configuraciones de mocks en el archivo JSON
-func saveMocksToFile() error {
-	// Serializa el mapa de configuraciones a JSON
-	data, err := json.MarshalIndent(mockConfigurations, "", "  ")
-	if err != nil {
-		log.Printf("Error al serializar mocks a JSON: %v", err)
-		return err
+	// Migrar las plantillas que usan referencias heredadas al contexto versionado actual
+	// Los mocks guardados antes de existir las revisiones inician en la revisión 1.
+	migrated := 0
+	for id, config := range ws.configs {
+		if config.Revision == 0 {
+			config.Revision = 1
+		}
+		if MigrateTemplateReferences(&config) {
+			migrated++
+		}
+		ws.configs[id] = config
 	}
+	if migrated > 0 {
+		log.Printf("Plantillas migradas a la versión %d del contexto: %d", models.CurrentTemplateVersion, migrated)
+		ws.save()
+	}
+}
 
-	// Escribe el JSON al archivo
-	if err := os.WriteFile(mocksFileName, data, 0644); err != nil {
-		log.Printf("Error al escribir mocks en el archivo '%s': %v", mocksFileName, err)
+// save guarda las configuraciones de mocks del workspace en su archivo JSON
+func (ws *mockWorkspace) save() error {
+	if err := writeWorkspaceFile(ws.mocksFile, ws.configs); err != nil {
+		log.Printf("Error al escribir mocks en el archivo '%s': %v", ws.mocksFile, err)
 		return err
 	}
 
-	log.Printf("Mocks guardados exitosamente en '%s'. Total: %d", mocksFileName, len(mockConfigurations))
+	log.Printf("Mocks guardados exitosamente en '%s'. Total: %d", ws.mocksFile, len(ws.configs))
 	return nil
 }
 
-// AddMockConfig agrega una nueva configuración de mock al almacenamiento.
-func AddMockConfig(config models.MockConfig) error {
+// AddMockConfig agrega una nueva configuración de mock al almacenamiento del workspace.
+// Si el ID ya existe la configuración se reemplaza y su revisión se incrementa.
+// author identifica a quien hizo el cambio en el historial.
+func AddMockConfig(workspace string, config models.MockConfig, author string) error {
 	mutex.Lock()
 	defer mutex.Unlock()
-	mockConfigurations[config.Id] = config
-	err := saveMocksToFile() // Guarda las configuraciones en el archivo después de agregar
+	ws := ensureWorkspace(workspace)
+	action := models.HistoryActionCreate
+	if _, exists := ws.configs[config.Id]; exists {
+		action = models.HistoryActionUpdate
+	}
+	config.Revision = ws.nextRevision(config.Id)
+	ws.configs[config.Id] = config
+	err := ws.save() // Guarda las configuraciones en el archivo después de agregar
 
 	if err != nil {
 		log.Printf("Error al guardar la configuración del mock '%s': %v", config.Id, err)
 		return err
 	}
 
+	ws.recordRevision(config.Id, config.Revision, action, author, &config, 0)
 	return nil
 }
 
-// GetMockConfigByID obtiene una configuración de mock por su ID.
-func GetMockConfigByID(id string) (models.MockConfig, bool) {
+// CreateMockConfig agrega una nueva configuración de mock y devuelve la configuración guardada. La revisión
+// empieza en 1, o continúa la numeración del historial si el ID perteneció a un mock eliminado.
+// Devuelve ErrMockExists si el ID ya está en uso.
+func CreateMockConfig(workspace string, config models.MockConfig, author string) (models.MockConfig, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ws := ensureWorkspace(workspace)
+	if _, exists := ws.configs[config.Id]; exists {
+		return config, ErrMockExists
+	}
+	config.Revision = ws.nextRevision(config.Id)
+	ws.configs[config.Id] = config
+	if err := ws.save(); err != nil {
+		return config, err
+	}
+	ws.recordRevision(config.Id, config.Revision, models.HistoryActionCreate, author, &config, 0)
+	return config, nil
+}
+
+// UpdateMockConfig reemplaza una configuración de mock existente si su revisión actual es expectedRevision,
+// y devuelve la configuración guardada con la revisión incrementada.
+// Devuelve ErrMockNotFound si el ID no existe y ErrMockConflict si la revisión cambió.
+func UpdateMockConfig(workspace string, config models.MockConfig, expectedRevision int, author string) (models.MockConfig, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ws := ensureWorkspace(workspace)
+	current, exists := ws.configs[config.Id]
+	if !exists {
+		return config, ErrMockNotFound
+	}
+	if current.Revision != expectedRevision {
+		return config, ErrMockConflict
+	}
+	config.Revision = ws.nextRevision(config.Id)
+	ws.configs[config.Id] = config
+	if err := ws.save(); err != nil {
+		return config, err
+	}
+	ws.recordRevision(config.Id, config.Revision, models.HistoryActionUpdate, author, &config, 0)
+	return config, nil
+}
+
+// GetMockConfigByID obtiene una configuración de mock del workspace por su ID.
+func GetMockConfigByID(workspace, id string) (models.MockConfig, bool) {
 	mutex.RLock()
 	defer mutex.RUnlock()
-	config, ok := mockConfigurations[id]
+	ws := getWorkspace(workspace)
+	if ws == nil {
+		return models.MockConfig{}, false
+	}
+	config, ok := ws.configs[id]
 	return config, ok
 }
 
-// GetAllMockConfigurations obtiene todas las configuraciones de mocks.
-func GetAllMockConfigurations() []models.MockConfig {
+// GetAllMockConfigurations obtiene todas las configuraciones de mocks del workspace.
+func GetAllMockConfigurations(workspace string) []models.MockConfig {
 	mutex.RLock()
 	defer mutex.RUnlock()
+	ws := getWorkspace(workspace)
+	if ws == nil {
+		return []models.MockConfig{}
+	}
 
 	// Convertir el mapa a un slice para facilitar la iteración
-	configs := make([]models.MockConfig, 0, len(mockConfigurations))
-	for _, config := range mockConfigurations {
+	configs := make([]models.MockConfig, 0, len(ws.configs))
+	for _, config := range ws.configs {
 		configs = append(configs, config)
 	}
 
-	// Ordenar las configuraciones por prioridad antes de devolverlas
+	// Ordenar las configuraciones por prioridad antes de devolverlas. El ID desempata para que, con la
+	// misma prioridad, el mock que responde no dependa del orden aleatorio del mapa.
 	sort.Slice(configs, func(i, j int) bool {
-		return configs[i].Priority > configs[j].Priority
+		if configs[i].Priority != configs[j].Priority {
+			return configs[i].Priority > configs[j].Priority
+		}
+		return configs[i].Id < configs[j].Id
 	})
 
 	return configs
 }
 
-// DeleteMockConfig elimina una configuración de mock por su ID y la guarda.
-func DeleteMockConfig(id string) bool {
+// DeleteMockConfigRevision elimina una configuración de mock si su revisión actual es expectedRevision.
+// Devuelve ErrMockNotFound si el ID no existe y ErrMockConflict si la revisión cambió.
+// La eliminación queda registrada en el historial, desde donde el mock puede restaurarse.
+func DeleteMockConfigRevision(workspace, id string, expectedRevision int, author string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ws := ensureWorkspace(workspace)
+	current, exists := ws.configs[id]
+	if !exists {
+		return ErrMockNotFound
+	}
+	if current.Revision != expectedRevision {
+		return ErrMockConflict
+	}
+	revision := ws.nextRevision(id)
+	delete(ws.configs, id)
+	if err := ws.save(); err != nil {
+		return err
+	}
+	ws.recordRevision(id, revision, models.HistoryActionDelete, author, nil, 0)
+	return nil
+}
+
+// ImportMockConfigs guarda un conjunto de mocks en una sola operación. Los mocks nuevos se crean, los existentes
+// se reemplazan y los idénticos a la configuración actual no generan una revisión nueva. Con replace, los mocks
+// que no están en el conjunto se eliminan. Con dryRun solo se calcula el resultado, sin modificar nada.
+// Devuelve el resultado de cada mock (en el mismo orden que configs) y los IDs eliminados.
+func ImportMockConfigs(workspace string, configs []models.MockConfig, replace, dryRun bool, author string) ([]models.MockImportResult, []string, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ws := ensureWorkspace(workspace)
+
+	results := make([]models.MockImportResult, len(configs))
+	imported := make(map[string]bool, len(configs))
+	changed := false
+	for i, config := range configs {
+		imported[config.Id] = true
+		result := models.MockImportResult{Index: i, Id: config.Id, Action: models.HistoryActionCreate}
+		if current, exists := ws.configs[config.Id]; exists {
+			result.Action = models.HistoryActionUpdate
+			result.Revision = current.Revision
+			if len(diffMockConfigs(&current, &config)) == 0 {
+				result.Action = models.ImportActionUnchanged
+			}
+		}
+		if result.Action != models.ImportActionUnchanged {
+			result.Revision = ws.nextRevision(config.Id)
+			changed = true
+			if !dryRun {
+				config.Revision = result.Revision
+				ws.configs[config.Id] = config
+				ws.appendRevision(config.Id, config.Revision, result.Action, author, &config, 0)
+			}
+		}
+		results[i] = result
+	}
+
+	deleted := []string{}
+	if replace {
+		for id := range ws.configs {
+			if imported[id] {
+				continue
+			}
+			deleted = append(deleted, id)
+			changed = true
+			if !dryRun {
+				revision := ws.nextRevision(id)
+				delete(ws.configs, id)
+				ws.appendRevision(id, revision, models.HistoryActionDelete, author, nil, 0)
+			}
+		}
+		sort.Strings(deleted)
+	}
+
+	if dryRun || !changed {
+		return results, deleted, nil
+	}
+	if err := ws.save(); err != nil {
+		return results, deleted, err
+	}
+	ws.saveHistory()
+	return results, deleted, nil
+}
+
+// SetMockEnabled habilita o deshabilita un mock. Si expectedRevision es distinto de 0 debe coincidir con
+// la revisión actual. Si el mock ya estaba en ese estado no se genera una revisión nueva y changed es falso.
+// Devuelve ErrMockNotFound si el ID no existe y ErrMockConflict si la revisión cambió.
+func SetMockEnabled(workspace, id string, enabled bool, expectedRevision int, author string) (config models.MockConfig, changed bool, err error) {
 	mutex.Lock()
 	defer mutex.Unlock()
-	_, exists := mockConfigurations[id]
-	if exists {
-		delete(mockConfigurations, id)
-		saveMocksToFile() // Guarda después de eliminar
-		return true
+	ws := ensureWorkspace(workspace)
+	config, exists := ws.configs[id]
+	if !exists {
+		return config, false, ErrMockNotFound
 	}
-	return false
+	if expectedRevision != 0 && config.Revision != expectedRevision {
+		return config, false, ErrMockConflict
+	}
+	if config.IsEnabled() == enabled {
+		return config, false, nil
+	}
+	config = ws.setEnabled(config, enabled, author)
+	if err := ws.save(); err != nil {
+		return config, true, err
+	}
+	ws.saveHistory()
+	return config, true, nil
+}
+
+// SetMocksEnabledByTag habilita o deshabilita todos los mocks del workspace con la etiqueta indicada.
+// Devuelve los mocks con la etiqueta (ordenados por ID) y cuáles de ellos cambiaron de estado.
+func SetMocksEnabledByTag(workspace, tag string, enabled bool, author string) ([]models.MockConfig, []string, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ws := ensureWorkspace(workspace)
+
+	var matched []models.MockConfig
+	changed := []string{}
+	for _, config := range ws.configs {
+		if !slices.Contains(config.Tags, tag) {
+			continue
+		}
+		if config.IsEnabled() != enabled {
+			config = ws.setEnabled(config, enabled, author)
+			changed = append(changed, config.Id)
+		}
+		matched = append(matched, config)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Id < matched[j].Id
+	})
+	sort.Strings(changed)
+
+	if len(changed) == 0 {
+		return matched, changed, nil
+	}
+	if err := ws.save(); err != nil {
+		return matched, changed, err
+	}
+	ws.saveHistory()
+	return matched, changed, nil
+}
+
+// setEnabled cambia el estado de un mock como una revisión nueva, sin guardar los archivos.
+// Debe llamarse con el mutex tomado.
+func (ws *mockWorkspace) setEnabled(config models.MockConfig, enabled bool, author string) models.MockConfig {
+	config.Enabled = &enabled
+	config.Revision = ws.nextRevision(config.Id)
+	ws.configs[config.Id] = config
+	ws.appendRevision(config.Id, config.Revision, models.HistoryActionUpdate, author, &config, 0)
+	return config
 }
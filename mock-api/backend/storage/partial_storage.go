@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"backend/models"
+)
+
+// Constante con el nombre del archivo de almacenamiento de partials, junto a mocks.json
+const partialsFileName = "config/partials.json"
+
+// Variables globales para almacenar los fragmentos de plantilla
+var (
+	templatePartials = make(map[string]models.TemplatePartial)
+	partialsMutex    sync.RWMutex
+)
+
+// InitPartialStorage carga los partials existentes desde el archivo.
+func InitPartialStorage() {
+	partialsMutex.Lock()
+	defer partialsMutex.Unlock()
+
+	data, err := os.ReadFile(partialsFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Printf("Archivo de partials '%s' no encontrado. Iniciando sin partials.", partialsFileName)
+			return
+		}
+		log.Printf("Error al leer el archivo de partials '%s': %v", partialsFileName, err)
+		return
+	}
+
+	if err := json.Unmarshal(data, &templatePartials); err != nil {
+		log.Printf("Error al deserializar partials desde '%s': %v. Iniciando sin partials.", partialsFileName, err)
+		templatePartials = make(map[string]models.TemplatePartial)
+	} else {
+		log.Printf("Partials cargados exitosamente desde '%s'. Total: %d", partialsFileName, len(templatePartials))
+	}
+}
+
+// savePartialsToFile guarda los partials en el archivo JSON
+func savePartialsToFile() error {
+	data, err := json.MarshalIndent(templatePartials, "", "  ")
+	if err != nil {
+		log.Printf("Error al serializar partials a JSON: %v", err)
+		return err
+	}
+
+	if err := os.WriteFile(partialsFileName, data, 0644); err != nil {
+		log.Printf("Error al escribir partials en el archivo '%s': %v", partialsFileName, err)
+		return err
+	}
+
+	log.Printf("Partials guardados exitosamente en '%s'. Total: %d", partialsFileName, len(templatePartials))
+	return nil
+}
+
+// AddPartial agrega o reemplaza un partial y lo guarda.
+func AddPartial(partial models.TemplatePartial) error {
+	partialsMutex.Lock()
+	defer partialsMutex.Unlock()
+	templatePartials[partial.Name] = partial
+	return savePartialsToFile()
+}
+
+// GetPartial obtiene un partial por su nombre.
+func GetPartial(name string) (models.TemplatePartial, bool) {
+	partialsMutex.RLock()
+	defer partialsMutex.RUnlock()
+	partial, ok := templatePartials[name]
+	return partial, ok
+}
+
+// GetAllPartials obtiene todos los partials ordenados por nombre.
+func GetAllPartials() []models.TemplatePartial {
+	partialsMutex.RLock()
+	defer partialsMutex.RUnlock()
+
+	partials := make([]models.TemplatePartial, 0, len(templatePartials))
+	for _, partial := range templatePartials {
+		partials = append(partials, partial)
+	}
+	sort.Slice(partials, func(i, j int) bool {
+		return partials[i].Name < partials[j].Name
+	})
+	return partials
+}
+
+// DeletePartial elimina un partial por su nombre y guarda los cambios.
+func DeletePartial(name string) bool {
+	partialsMutex.Lock()
+	defer partialsMutex.Unlock()
+	if _, exists := templatePartials[name]; !exists {
+		return false
+	}
+	delete(templatePartials, name)
+	savePartialsToFile()
+	return true
+}
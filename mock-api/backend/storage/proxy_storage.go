@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"backend/models"
+)
+
+// Constante con el nombre del archivo donde se guarda la configuración del proxy
+const proxyFileName = "config/proxy.json"
+
+// Variables globales para la configuración del proxy
+var (
+	proxyConfig models.ProxyConfig
+	proxyMutex  sync.RWMutex
+)
+
+// InitProxyStorage carga la configuración del proxy desde el archivo.
+func InitProxyStorage() {
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+
+	data, err := os.ReadFile(proxyFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Printf("Archivo de proxy '%s' no encontrado. Iniciando sin proxy.", proxyFileName)
+			return
+		}
+		log.Printf("Error al leer el archivo de proxy '%s': %v", proxyFileName, err)
+		return
+	}
+
+	if err := json.Unmarshal(data, &proxyConfig); err != nil {
+		log.Printf("Error al deserializar la configuración de proxy desde '%s': %v. Iniciando sin proxy.", proxyFileName, err)
+		proxyConfig = models.ProxyConfig{}
+		return
+	}
+	log.Printf("Configuración de proxy cargada exitosamente desde '%s'. Rutas: %d", proxyFileName, len(proxyConfig.Routes))
+}
+
+// GetProxyConfig obtiene la configuración actual del proxy.
+func GetProxyConfig() models.ProxyConfig {
+	proxyMutex.RLock()
+	defer proxyMutex.RUnlock()
+	config := proxyConfig
+	config.Routes = append([]models.ProxyRoute(nil), proxyConfig.Routes...)
+	return config
+}
+
+// SaveProxyConfig reemplaza la configuración del proxy y la guarda.
+func SaveProxyConfig(config models.ProxyConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		log.Printf("Error al serializar la configuración de proxy a JSON: %v", err)
+		return err
+	}
+
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+	if err := os.WriteFile(proxyFileName, data, 0644); err != nil {
+		log.Printf("Error al escribir la configuración de proxy en el archivo '%s': %v", proxyFileName, err)
+		return err
+	}
+	proxyConfig = config
+	log.Printf("Configuración de proxy guardada exitosamente en '%s'. Rutas: %d", proxyFileName, len(config.Routes))
+	return nil
+}
+
+// ResolveProxyUpstream busca el upstream para una ruta: la ruta por prefijo más larga que coincida
+// (por segmentos completos) o, si ninguna coincide, el upstream global. Devuelve también la ruta
+// que debe reenviarse (sin el prefijo si la ruta usa StripPrefix).
+func ResolveProxyUpstream(path string) (string, string, bool) {
+	proxyMutex.RLock()
+	defer proxyMutex.RUnlock()
+
+	var best *models.ProxyRoute
+	for i, route := range proxyConfig.Routes {
+		prefix := strings.TrimSuffix(route.PathPrefix, "/")
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		if best == nil || len(route.PathPrefix) > len(best.PathPrefix) {
+			best = &proxyConfig.Routes[i]
+		}
+	}
+
+	if best != nil {
+		forwardPath := path
+		if best.StripPrefix {
+			forwardPath = strings.TrimPrefix(path, strings.TrimSuffix(best.PathPrefix, "/"))
+			if forwardPath == "" {
+				forwardPath = "/"
+			}
+		}
+		return best.Upstream, forwardPath, true
+	}
+	if proxyConfig.DefaultUpstream != "" {
+		return proxyConfig.DefaultUpstream, path, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"backend/models"
+)
+
+// ErrRecordingActive se devuelve al iniciar una grabación cuando ya hay otra en curso.
+var ErrRecordingActive = errors.New("ya hay una grabación en curso")
+
+// defaultRecordingLimit es la cantidad máxima de pares que guarda una grabación; los siguientes se reenvían
+// pero no se guardan. Se puede ajustar con la variable de entorno RECORDING_LIMIT.
+const defaultRecordingLimit = 1000
+
+// Estado de la grabación actual. Se mantiene solo en memoria.
+var (
+	recordingConfig    *models.RecordingConfig
+	recordingStartedAt time.Time
+	recordedExchanges  []models.RecordedExchange
+	recordingDropped   int
+	recordingMutex     sync.RWMutex
+)
+
+// recordingLimit devuelve la cantidad máxima de pares que guarda una grabación.
+func recordingLimit() int {
+	if v, err := strconv.Atoi(os.Getenv("RECORDING_LIMIT")); err == nil && v > 0 {
+		return v
+	}
+	return defaultRecordingLimit
+}
+
+// StartRecording inicia una grabación con la configuración indicada.
+func StartRecording(config models.RecordingConfig) error {
+	recordingMutex.Lock()
+	defer recordingMutex.Unlock()
+	if recordingConfig != nil {
+		return ErrRecordingActive
+	}
+	recordingConfig = &config
+	recordingStartedAt = time.Now().UTC()
+	recordedExchanges = nil
+	recordingDropped = 0
+	return nil
+}
+
+// ActiveRecording obtiene la configuración de la grabación en curso, si existe.
+func ActiveRecording() (models.RecordingConfig, bool) {
+	recordingMutex.RLock()
+	defer recordingMutex.RUnlock()
+	if recordingConfig == nil {
+		return models.RecordingConfig{}, false
+	}
+	return *recordingConfig, true
+}
+
+// AddRecordedExchange guarda un par solicitud/respuesta si hay una grabación en curso. Al llegar al límite
+// de la grabación el par se descarta y solo se cuenta. Devuelve false si el par no se guardó.
+func AddRecordedExchange(exchange models.RecordedExchange) bool {
+	recordingMutex.Lock()
+	defer recordingMutex.Unlock()
+	if recordingConfig == nil {
+		return false
+	}
+	if len(recordedExchanges) >= recordingLimit() {
+		recordingDropped++
+		return false
+	}
+	recordedExchanges = append(recordedExchanges, exchange)
+	return true
+}
+
+// GetRecordingStatus obtiene el estado de la grabación actual.
+func GetRecordingStatus() models.RecordingStatus {
+	recordingMutex.RLock()
+	defer recordingMutex.RUnlock()
+	if recordingConfig == nil {
+		return models.RecordingStatus{}
+	}
+	config := *recordingConfig
+	startedAt := recordingStartedAt
+	return models.RecordingStatus{Active: true, Config: &config, StartedAt: &startedAt, Recorded: len(recordedExchanges), Dropped: recordingDropped}
+}
+
+// StopRecording detiene la grabación en curso y devuelve su configuración, los pares capturados y la
+// cantidad de pares descartados por el límite. Devuelve false si no había una grabación en curso.
+func StopRecording() (models.RecordingConfig, []models.RecordedExchange, int, bool) {
+	recordingMutex.Lock()
+	defer recordingMutex.Unlock()
+	if recordingConfig == nil {
+		return models.RecordingConfig{}, nil, 0, false
+	}
+	config, exchanges, dropped := *recordingConfig, recordedExchanges, recordingDropped
+	recordingConfig = nil
+	recordedExchanges = nil
+	recordingDropped = 0
+	return config, exchanges, dropped, true
+}
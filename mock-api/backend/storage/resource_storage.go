@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"backend/models"
+
+	"github.com/google/uuid"
+)
+
+// Constante con el nombre del archivo donde se guardan las definiciones de recursos.
+// Los datos de cada recurso viven solo en memoria y se reinician con los datos semilla.
+const resourcesFileName = "config/resources.json"
+
+// Errores devueltos por las operaciones CRUD sobre recursos
+var (
+	ErrResourceNotFound = errors.New("recurso no encontrado")
+	ErrItemNotFound     = errors.New("elemento no encontrado")
+	ErrItemConflict     = errors.New("ya existe un elemento con el mismo ID")
+)
+
+// resourceCollection mantiene los elementos de un recurso en orden de inserción.
+type resourceCollection struct {
+	order []string
+	items map[string]map[string]interface{}
+}
+
+// Variables globales para las definiciones y los datos de los recursos
+var (
+	resourceConfigs = make(map[string]models.ResourceConfig)
+	resourceData    = make(map[string]*resourceCollection)
+	resourceMutex   sync.RWMutex
+)
+
+// InitResourceStorage carga las definiciones de recursos y sus datos semilla.
+func InitResourceStorage() {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+
+	data, err := os.ReadFile(resourcesFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Printf("Archivo de recursos '%s' no encontrado. Iniciando sin recursos.", resourcesFileName)
+			return
+		}
+		log.Printf("Error al leer el archivo de recursos '%s': %v", resourcesFileName, err)
+		return
+	}
+
+	if err := json.Unmarshal(data, &resourceConfigs); err != nil {
+		log.Printf("Error al deserializar recursos desde '%s': %v. Iniciando sin recursos.", resourcesFileName, err)
+		resourceConfigs = make(map[string]models.ResourceConfig)
+		return
+	}
+
+	for name, config := range resourceConfigs {
+		resourceData[name] = seedCollection(config)
+	}
+	log.Printf("Recursos cargados exitosamente desde '%s'. Total: %d", resourcesFileName, len(resourceConfigs))
+}
+
+// saveResourcesToFile guarda las definiciones de recursos en el archivo JSON
+func saveResourcesToFile() error {
+	data, err := json.MarshalIndent(resourceConfigs, "", "  ")
+	if err != nil {
+		log.Printf("Error al serializar recursos a JSON: %v", err)
+		return err
+	}
+	if err := os.WriteFile(resourcesFileName, data, 0644); err != nil {
+		log.Printf("Error al escribir recursos en el archivo '%s': %v", resourcesFileName, err)
+		return err
+	}
+	log.Printf("Recursos guardados exitosamente en '%s'. Total: %d", resourcesFileName, len(resourceConfigs))
+	return nil
+}
+
+// seedCollection crea la colección inicial de un recurso a partir de sus datos semilla.
+func seedCollection(config models.ResourceConfig) *resourceCollection {
+	collection := &resourceCollection{items: make(map[string]map[string]interface{})}
+	for _, item := range config.Seed {
+		copied := copyItem(item)
+		if _, err := collection.insert(config.IdField, copied); err != nil {
+			log.Printf("Advertencia: elemento semilla ignorado en el recurso '%s': %v", config.Name, err)
+		}
+	}
+	return collection
+}
+
+// copyItem crea una copia profunda de un elemento para no compartir mapas con quien lo envía.
+func copyItem(item map[string]interface{}) map[string]interface{} {
+	data, _ := json.Marshal(item)
+	var copied map[string]interface{}
+	json.Unmarshal(data, &copied)
+	return copied
+}
+
+// itemID convierte el valor del campo ID a string para usarlo como clave.
+func itemID(v interface{}) string {
+	switch id := v.(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(id)
+	}
+}
+
+// nextID genera un ID para un elemento nuevo: el siguiente entero si todos los IDs existentes
+// son numéricos, o un UUID en caso contrario.
+func (rc *resourceCollection) nextID() interface{} {
+	max := 0.0
+	for _, id := range rc.order {
+		n, err := strconv.ParseFloat(id, 64)
+		if err != nil {
+			return uuid.New().String()
+		}
+		if n > max {
+			max = n
+		}
+	}
+	return max + 1
+}
+
+// insert agrega un elemento nuevo, generando su ID si no lo tiene.
+func (rc *resourceCollection) insert(idField string, item map[string]interface{}) (map[string]interface{}, error) {
+	if item[idField] == nil || item[idField] == "" {
+		item[idField] = rc.nextID()
+	}
+	id := itemID(item[idField])
+	if _, exists := rc.items[id]; exists {
+		return nil, ErrItemConflict
+	}
+	rc.items[id] = item
+	rc.order = append(rc.order, id)
+	return item, nil
+}
+
+// SaveResourceConfig agrega o reemplaza la definición de un recurso y reinicia sus datos con la semilla.
+func SaveResourceConfig(config models.ResourceConfig) error {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+	resourceConfigs[config.Name] = config
+	resourceData[config.Name] = seedCollection(config)
+	return saveResourcesToFile()
+}
+
+// GetResourceConfig obtiene la definición de un recurso por su nombre.
+func GetResourceConfig(name string) (models.ResourceConfig, bool) {
+	resourceMutex.RLock()
+	defer resourceMutex.RUnlock()
+	config, ok := resourceConfigs[name]
+	return config, ok
+}
+
+// GetAllResourceConfigs obtiene todas las definiciones de recursos ordenadas por nombre.
+func GetAllResourceConfigs() []models.ResourceConfig {
+	resourceMutex.RLock()
+	defer resourceMutex.RUnlock()
+	configs := make([]models.ResourceConfig, 0, len(resourceConfigs))
+	for _, config := range resourceConfigs {
+		configs = append(configs, config)
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Name < configs[j].Name
+	})
+	return configs
+}
+
+// DeleteResourceConfig elimina un recurso y sus datos.
+func DeleteResourceConfig(name string) bool {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+	if _, exists := resourceConfigs[name]; !exists {
+		return false
+	}
+	delete(resourceConfigs, name)
+	delete(resourceData, name)
+	saveResourcesToFile()
+	return true
+}
+
+// ResetResourceData reinicia los datos de un recurso con su semilla.
+func ResetResourceData(name string) bool {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+	config, exists := resourceConfigs[name]
+	if !exists {
+		return false
+	}
+	resourceData[name] = seedCollection(config)
+	return true
+}
+
+// ListResourceItems devuelve una copia de todos los elementos de un recurso en orden de inserción.
+func ListResourceItems(name string) ([]map[string]interface{}, error) {
+	resourceMutex.RLock()
+	defer resourceMutex.RUnlock()
+	collection, ok := resourceData[name]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	items := make([]map[string]interface{}, 0, len(collection.order))
+	for _, id := range collection.order {
+		items = append(items, copyItem(collection.items[id]))
+	}
+	return items, nil
+}
+
+// GetResourceItem obtiene un elemento por su ID.
+func GetResourceItem(name, id string) (map[string]interface{}, error) {
+	resourceMutex.RLock()
+	defer resourceMutex.RUnlock()
+	collection, ok := resourceData[name]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	item, ok := collection.items[id]
+	if !ok {
+		return nil, ErrItemNotFound
+	}
+	return copyItem(item), nil
+}
+
+// CreateResourceItem agrega un elemento nuevo. Devuelve ErrItemConflict si el ID ya existe.
+func CreateResourceItem(name string, item map[string]interface{}) (map[string]interface{}, error) {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+	collection, ok := resourceData[name]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	created, err := collection.insert(resourceConfigs[name].IdField, copyItem(item))
+	if err != nil {
+		return nil, err
+	}
+	return copyItem(created), nil
+}
+
+// ReplaceResourceItem reemplaza un elemento existente conservando su ID.
+func ReplaceResourceItem(name, id string, item map[string]interface{}) (map[string]interface{}, error) {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+	collection, ok := resourceData[name]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	existing, ok := collection.items[id]
+	if !ok {
+		return nil, ErrItemNotFound
+	}
+	idField := resourceConfigs[name].IdField
+	replaced := copyItem(item)
+	replaced[idField] = existing[idField]
+	collection.items[id] = replaced
+	return copyItem(replaced), nil
+}
+
+// UpdateResourceItem aplica una función de actualización (ej. JSON Merge Patch) a un elemento existente
+// de forma atómica. El ID del elemento no puede modificarse.
+func UpdateResourceItem(name, id string, update func(map[string]interface{}) map[string]interface{}) (map[string]interface{}, error) {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+	collection, ok := resourceData[name]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	existing, ok := collection.items[id]
+	if !ok {
+		return nil, ErrItemNotFound
+	}
+	idField := resourceConfigs[name].IdField
+	updated := update(copyItem(existing))
+	updated[idField] = existing[idField]
+	collection.items[id] = updated
+	return copyItem(updated), nil
+}
+
+// DeleteResourceItem elimina un elemento por su ID.
+func DeleteResourceItem(name, id string) error {
+	resourceMutex.Lock()
+	defer resourceMutex.Unlock()
+	collection, ok := resourceData[name]
+	if !ok {
+		return ErrResourceNotFound
+	}
+	if _, ok := collection.items[id]; !ok {
+		return ErrItemNotFound
+	}
+	delete(collection.items, id)
+	for i, existing := range collection.order {
+		if existing == id {
+			collection.order = append(collection.order[:i], collection.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
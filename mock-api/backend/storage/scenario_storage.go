@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"backend/models"
+)
+
+// Estados actuales de los escenarios por workspace. Se mantienen solo en memoria: al reiniciar el servidor
+// todos los escenarios vuelven a models.ScenarioStarted.
+var (
+	scenarioStates = make(map[string]map[string]string)
+	scenarioMutex  sync.RWMutex
+)
+
+// GetScenarioState obtiene el estado actual de un escenario del workspace.
+func GetScenarioState(workspace, name string) string {
+	scenarioMutex.RLock()
+	defer scenarioMutex.RUnlock()
+	if state, ok := scenarioStates[workspace][name]; ok {
+		return state
+	}
+	return models.ScenarioStarted
+}
+
+// SetScenarioState cambia el estado de un escenario del workspace.
+func SetScenarioState(workspace, name, state string) {
+	scenarioMutex.Lock()
+	defer scenarioMutex.Unlock()
+	workspaceScenarios(workspace)[name] = state
+}
+
+// workspaceScenarios devuelve los estados de los escenarios del workspace y los crea si no existen.
+// Debe llamarse con scenarioMutex tomado para escritura.
+func workspaceScenarios(workspace string) map[string]string {
+	states, ok := scenarioStates[workspace]
+	if !ok {
+		states = make(map[string]string)
+		scenarioStates[workspace] = states
+	}
+	return states
+}
+
+// TransitionScenario cambia el estado de un escenario solo si su estado actual es 'from'
+// (o si 'from' está vacío). Devuelve false si otra solicitud ya cambió el estado.
+func TransitionScenario(workspace, name, from, to string) bool {
+	scenarioMutex.Lock()
+	defer scenarioMutex.Unlock()
+	states := workspaceScenarios(workspace)
+	current, ok := states[name]
+	if !ok {
+		current = models.ScenarioStarted
+	}
+	if from != "" && current != from {
+		return false
+	}
+	states[name] = to
+	return true
+}
+
+// ResetScenario devuelve un escenario del workspace a su estado inicial.
+func ResetScenario(workspace, name string) {
+	scenarioMutex.Lock()
+	defer scenarioMutex.Unlock()
+	delete(scenarioStates[workspace], name)
+}
+
+// ResetAllScenarios devuelve todos los escenarios del workspace a su estado inicial.
+func ResetAllScenarios(workspace string) {
+	scenarioMutex.Lock()
+	defer scenarioMutex.Unlock()
+	delete(scenarioStates, workspace)
+}
+
+// GetAllScenarios obtiene los escenarios referenciados por los mocks del workspace (o con estado asignado),
+// con su estado actual y los estados posibles, ordenados por nombre.
+func GetAllScenarios(workspace string) []models.ScenarioState {
+	possible := make(map[string]map[string]bool)
+	addState := func(scenario, state string) {
+		if possible[scenario] == nil {
+			possible[scenario] = map[string]bool{models.ScenarioStarted: true}
+		}
+		if state != "" {
+			possible[scenario][state] = true
+		}
+	}
+
+	for _, config := range GetAllMockConfigurations(workspace) {
+		if config.Scenario == "" {
+			continue
+		}
+		addState(config.Scenario, config.RequiredScenarioState)
+		addState(config.Scenario, config.NewScenarioState)
+	}
+
+	scenarioMutex.RLock()
+	for name, state := range scenarioStates[workspace] {
+		addState(name, state)
+	}
+	scenarioMutex.RUnlock()
+
+	scenarios := make([]models.ScenarioState, 0, len(possible))
+	for name, states := range possible {
+		list := make([]string, 0, len(states))
+		for state := range states {
+			list = append(list, state)
+		}
+		sort.Strings(list)
+		scenarios = append(scenarios, models.ScenarioState{Name: name, State: GetScenarioState(workspace, name), PossibleStates: list})
+	}
+	sort.Slice(scenarios, func(i, j int) bool {
+		return scenarios[i].Name < scenarios[j].Name
+	})
+	return scenarios
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// DefaultStoreNamespace es el namespace usado cuando no se indica uno.
+const DefaultStoreNamespace = "default"
+
+// Datos del store clave-valor, agrupados por namespace. Se mantienen solo en memoria:
+// al reiniciar el servidor el store queda vacío.
+var (
+	storeData  = make(map[string]map[string]interface{})
+	storeMutex sync.RWMutex
+)
+
+// cloneStoreValue copia un valor mediante JSON para que el store no comparta mapas ni listas
+// con las plantillas o scripts que lo leen o escriben.
+func cloneStoreValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var clone interface{}
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// GetStoreValue obtiene el valor de una clave dentro de un namespace.
+func GetStoreValue(namespace, key string) (interface{}, bool) {
+	storeMutex.RLock()
+	defer storeMutex.RUnlock()
+	value, ok := storeData[namespace][key]
+	if !ok {
+		return nil, false
+	}
+	clone, err := cloneStoreValue(value)
+	if err != nil {
+		return nil, false
+	}
+	return clone, true
+}
+
+// SetStoreValue guarda el valor de una clave dentro de un namespace.
+func SetStoreValue(namespace, key string, value interface{}) error {
+	clone, err := cloneStoreValue(value)
+	if err != nil {
+		return err
+	}
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	if storeData[namespace] == nil {
+		storeData[namespace] = make(map[string]interface{})
+	}
+	storeData[namespace][key] = clone
+	return nil
+}
+
+// SeedStoreNamespace guarda varias claves a la vez en un namespace, conservando las existentes.
+func SeedStoreNamespace(namespace string, values map[string]interface{}) error {
+	clones := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		clone, err := cloneStoreValue(value)
+		if err != nil {
+			return err
+		}
+		clones[key] = clone
+	}
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	if storeData[namespace] == nil {
+		storeData[namespace] = make(map[string]interface{})
+	}
+	for key, value := range clones {
+		storeData[namespace][key] = value
+	}
+	return nil
+}
+
+// DeleteStoreValue elimina una clave de un namespace. Devuelve false si no existía.
+func DeleteStoreValue(namespace, key string) bool {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	if _, ok := storeData[namespace][key]; !ok {
+		return false
+	}
+	delete(storeData[namespace], key)
+	if len(storeData[namespace]) == 0 {
+		delete(storeData, namespace)
+	}
+	return true
+}
+
+// GetStoreNamespace obtiene una copia de todas las claves de un namespace.
+func GetStoreNamespace(namespace string) (map[string]interface{}, bool) {
+	storeMutex.RLock()
+	defer storeMutex.RUnlock()
+	values, ok := storeData[namespace]
+	if !ok {
+		return nil, false
+	}
+	clone, err := cloneStoreValue(values)
+	if err != nil {
+		return nil, false
+	}
+	return clone.(map[string]interface{}), true
+}
+
+// GetStoreNamespaces obtiene los nombres de los namespaces con datos, ordenados.
+func GetStoreNamespaces() []string {
+	storeMutex.RLock()
+	defer storeMutex.RUnlock()
+	names := make([]string, 0, len(storeData))
+	for name := range storeData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClearStoreNamespace elimina todas las claves de un namespace. Devuelve false si no existía.
+func ClearStoreNamespace(namespace string) bool {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	if _, ok := storeData[namespace]; !ok {
+		return false
+	}
+	delete(storeData, namespace)
+	return true
+}
+
+// ClearStore elimina todos los datos del store.
+func ClearStore() {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	storeData = make(map[string]map[string]interface{})
+}
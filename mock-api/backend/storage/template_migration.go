@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+
+	"backend/models"
+)
+
+// templateActionRegex encuentra las acciones '{{ ... }}' de una plantilla, incluidas las que ocupan varias líneas.
+var templateActionRegex = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// legacyFieldRegex encuentra referencias heredadas al inicio de una cadena de campos,
+// por ejemplo '.queryParams.name' pero no '.Request.Query.name' ni '$item.headers'.
+var legacyFieldRegex = regexp.MustCompile(`(^|[^\w.\]\)$])\.(queryParams|bodyParams|headers|pathParams)\b((?:\.[\w-]+)?)`)
+
+// legacyFieldMap traduce los nombres heredados (los mismos campos de MockConfig) a su ubicación en el contexto versionado.
+var legacyFieldMap = map[string]string{
+	"queryParams": ".Request.Query",
+	"bodyParams":  ".Request.Body",
+	"headers":     ".Request.Headers",
+	"pathParams":  ".Request.PathParams",
+}
+
+// MigrateTemplateReferences reescribe las referencias heredadas de una plantilla
+// ('{{.queryParams.name}}', '{{.headers.Authorization}}'...) al contexto versionado actual
+// ('{{.Request.Query.name}}', '{{.Request.Headers.authorization}}').
+// Devuelve true si la configuración fue modificada.
+func MigrateTemplateReferences(config *models.MockConfig) bool {
+	if !config.IsTemplate || config.TemplateVersion >= models.CurrentTemplateVersion {
+		return false
+	}
+
+	// Las plantillas Handlebars usan la convención 'request.query.name' y no requieren migración
+	if config.TemplateEngine == models.TemplateEngineHandlebars {
+		config.TemplateVersion = models.CurrentTemplateVersion
+		return true
+	}
+
+	if body, ok := config.ResponseBody.(string); ok {
+		config.ResponseBody = migrateTemplateString(body)
+	}
+	config.ResponseStatusCodeTemplate = migrateTemplateString(config.ResponseStatusCodeTemplate)
+	for name, value := range config.ResponseHeaders {
+		config.ResponseHeaders[name] = migrateTemplateString(value)
+	}
+	config.TemplateVersion = models.CurrentTemplateVersion
+	return true
+}
+
+// migrateTemplateString aplica la migración únicamente dentro de las acciones de la plantilla
+// que se evalúan con el contexto raíz. Dentro de bloques 'range' y 'with' el punto cambia,
+// por lo que esas acciones se dejan intactas; su rama '{{else}}' vuelve a usar el punto exterior.
+func migrateTemplateString(tmpl string) string {
+	var blocks []bool // true si el bloque cambia el valor de '.'
+	insideDotBlock := func() bool {
+		for _, changesDot := range blocks {
+			if changesDot {
+				return true
+			}
+		}
+		return false
+	}
+
+	return templateActionRegex.ReplaceAllStringFunc(tmpl, func(action string) string {
+		keyword := strings.Fields(strings.Trim(action, "{}- \t\r\n"))
+		first := ""
+		if len(keyword) > 0 {
+			first = keyword[0]
+		}
+
+		// La condición de un 'range'/'with' aún se evalúa con el punto exterior
+		rewrite := !insideDotBlock()
+		switch first {
+		case "range", "with":
+			blocks = append(blocks, true)
+		case "if", "block":
+			blocks = append(blocks, false)
+		case "else":
+			// La rama else (y la condición de un 'else if'/'else with') se evalúa con el punto exterior
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+				rewrite = !insideDotBlock()
+				blocks = append(blocks, len(keyword) > 1 && keyword[1] == "with")
+			}
+		case "end":
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+		}
+		if !rewrite {
+			return action
+		}
+
+		return legacyFieldRegex.ReplaceAllStringFunc(action, func(ref string) string {
+			m := legacyFieldRegex.FindStringSubmatch(ref)
+			prefix, field, rest := m[1], m[2], m[3]
+			target := legacyFieldMap[field]
+
+			// Los headers se exponen en minúsculas; si el nombre tiene guiones se usa getMapValue
+			if field == "headers" && rest != "" {
+				name := strings.ToLower(strings.TrimPrefix(rest, "."))
+				if strings.Contains(name, "-") {
+					return prefix + `(getMapValue ` + target + ` "` + name + `")`
+				}
+				return prefix + target + "." + name
+			}
+			return prefix + target + rest
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"testing"
+
+	"backend/models"
+)
+
+func TestMigrateTemplateString(t *testing.T) {
+	tests := map[string]string{
+		`{{.queryParams.name}}`:                                      `{{.Request.Query.name}}`,
+		`{{.headers.Authorization}}`:                                 `{{.Request.Headers.authorization}}`,
+		`{{.headers.X-Request-Id}}`:                                  `{{(getMapValue .Request.Headers "x-request-id")}}`,
+		`{{ json .bodyParams }}`:                                     `{{ json .Request.Body }}`,
+		"{{ if\n  .pathParams.id }}x{{ end }}":                       "{{ if\n  .Request.PathParams.id }}x{{ end }}",
+		`{{$q := .queryParams}}{{$q.headers}}`:                       `{{$q := .Request.Query}}{{$q.headers}}`,
+		`{{.Request.Query.headers}}`:                                 `{{.Request.Query.headers}}`,
+		`{{range .bodyParams.items}}{{.headers}}{{end}}{{.headers}}`: `{{range .Request.Body.items}}{{.headers}}{{end}}{{.Request.Headers}}`,
+		`{{with .bodyParams.user}}{{.queryParams}}{{end}}`:           `{{with .Request.Body.user}}{{.queryParams}}{{end}}`,
+		`{{if .bodyParams}}{{.headers.a}}{{end}}`:                    `{{if .Request.Body}}{{.Request.Headers.a}}{{end}}`,
+
+		// La rama else de range/with se ejecuta con el punto exterior
+		`{{range .bodyParams.items}}{{.id}}{{else}}{{.queryParams.empty}}{{end}}`:                   `{{range .Request.Body.items}}{{.id}}{{else}}{{.Request.Query.empty}}{{end}}`,
+		`{{with .bodyParams.user}}{{.name}}{{else}}{{.headers.x}}{{end}}`:                           `{{with .Request.Body.user}}{{.name}}{{else}}{{.Request.Headers.x}}{{end}}`,
+		`{{with .bodyParams.a}}{{.x}}{{else with .bodyParams.b}}{{.queryParams}}{{end}}`:            `{{with .Request.Body.a}}{{.x}}{{else with .Request.Body.b}}{{.queryParams}}{{end}}`,
+		`{{range .bodyParams.l}}{{if .ok}}{{.headers}}{{else}}{{.headers}}{{end}}{{end}}`:           `{{range .Request.Body.l}}{{if .ok}}{{.headers}}{{else}}{{.headers}}{{end}}{{end}}`,
+		`{{if .bodyParams.a}}{{range .bodyParams.l}}{{.x}}{{end}}{{else}}{{.queryParams.b}}{{end}}`: `{{if .Request.Body.a}}{{range .Request.Body.l}}{{.x}}{{end}}{{else}}{{.Request.Query.b}}{{end}}`,
+	}
+	for tmpl, want := range tests {
+		if got := migrateTemplateString(tmpl); got != want {
+			t.Errorf("migrateTemplateString(%q)\n  = %q\nse esperaba %q", tmpl, got, want)
+		}
+	}
+}
+
+func TestMigrateTemplateReferences(t *testing.T) {
+	config := models.MockConfig{
+		IsTemplate:                 true,
+		ResponseBody:               `{"id": "{{.pathParams.id}}"}`,
+		ResponseStatusCodeTemplate: `{{if .queryParams.missing}}404{{else}}200{{end}}`,
+		ResponseHeaders:            map[string]string{"X-Echo": "{{.headers.x-echo}}"},
+	}
+	if !MigrateTemplateReferences(&config) {
+		t.Fatal("se esperaba que la plantilla se migrara")
+	}
+	if config.ResponseBody != `{"id": "{{.Request.PathParams.id}}"}` ||
+		config.ResponseStatusCodeTemplate != `{{if .Request.Query.missing}}404{{else}}200{{end}}` ||
+		config.ResponseHeaders["X-Echo"] != `{{(getMapValue .Request.Headers "x-echo")}}` ||
+		config.TemplateVersion != models.CurrentTemplateVersion {
+		t.Errorf("configuración migrada inesperada: %+v", config)
+	}
+
+	// Una plantilla ya migrada no se vuelve a modificar
+	if MigrateTemplateReferences(&config) {
+		t.Error("no se esperaba migrar una plantilla con la versión actual")
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"backend/models"
+)
+
+// DefaultWorkspace es el workspace de las solicitudes que no indican otro. Sus mocks se guardan en
+// config/mocks.json y config/history.json, como antes de existir los workspaces.
+const DefaultWorkspace = "default"
+
+// Directorio donde se guardan los mocks y el historial de los demás workspaces, uno por subdirectorio.
+const workspacesDir = "config/workspaces"
+
+// mockWorkspace es el conjunto de mocks de un workspace, con su historial de revisiones y los archivos
+// donde se guardan. Se protege con el mutex del almacenamiento de mocks.
+type mockWorkspace struct {
+	name        string
+	configs     map[string]models.MockConfig
+	history     map[string][]models.MockHistoryEntry
+	mocksFile   string
+	historyFile string
+	dedicated   bool // Tiene un puerto dedicado: existe aunque no tenga mocks
+}
+
+// workspaces guarda los workspaces conocidos por nombre. Los que no tienen mocks se crean al primer cambio.
+var workspaces = make(map[string]*mockWorkspace)
+
+// newMockWorkspace crea un workspace vacío con las rutas de sus archivos.
+func newMockWorkspace(name string) *mockWorkspace {
+	ws := &mockWorkspace{
+		name:        name,
+		configs:     make(map[string]models.MockConfig),
+		history:     make(map[string][]models.MockHistoryEntry),
+		mocksFile:   mocksFileName,
+		historyFile: historyFileName,
+	}
+	if name != DefaultWorkspace {
+		ws.mocksFile = filepath.Join(workspacesDir, name, "mocks.json")
+		ws.historyFile = filepath.Join(workspacesDir, name, "history.json")
+	}
+	return ws
+}
+
+// getWorkspace devuelve el workspace indicado, o nil si todavía no existe. Debe llamarse con el mutex tomado.
+func getWorkspace(name string) *mockWorkspace {
+	return workspaces[name]
+}
+
+// ensureWorkspace devuelve el workspace indicado y lo crea si no existe. Debe llamarse con el mutex tomado
+// para escritura.
+func ensureWorkspace(name string) *mockWorkspace {
+	ws, ok := workspaces[name]
+	if !ok {
+		ws = newMockWorkspace(name)
+		workspaces[name] = ws
+	}
+	return ws
+}
+
+// loadWorkspaces carga los workspaces guardados en config/workspaces. Debe llamarse con el mutex tomado.
+func loadWorkspaces() {
+	entries, err := os.ReadDir(workspacesDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error al leer el directorio de workspaces '%s': %v", workspacesDir, err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == DefaultWorkspace {
+			continue
+		}
+		ws := newMockWorkspace(entry.Name())
+		ws.load()
+		workspaces[ws.name] = ws
+	}
+}
+
+// RegisterWorkspace registra un workspace con puerto dedicado, que existe desde el inicio aunque no tenga mocks.
+func RegisterWorkspace(name string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	ensureWorkspace(name).dedicated = true
+}
+
+// exists indica si el workspace se considera creado: tiene mocks, historial o un puerto dedicado.
+func (ws *mockWorkspace) exists() bool {
+	return ws.name == DefaultWorkspace || ws.dedicated || len(ws.configs) > 0 || len(ws.history) > 0
+}
+
+// WorkspaceExists indica si existe el workspace. El workspace por defecto siempre existe.
+func WorkspaceExists(name string) bool {
+	if name == DefaultWorkspace {
+		return true
+	}
+	mutex.RLock()
+	defer mutex.RUnlock()
+	ws := getWorkspace(name)
+	return ws != nil && ws.exists()
+}
+
+// GetWorkspaces obtiene los workspaces con mocks, historial o un puerto dedicado, junto con su cantidad de
+// mocks, ordenados por nombre. El workspace por defecto siempre se incluye.
+func GetWorkspaces() []models.WorkspaceInfo {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	infos := []models.WorkspaceInfo{}
+	if getWorkspace(DefaultWorkspace) == nil {
+		infos = append(infos, models.WorkspaceInfo{Name: DefaultWorkspace})
+	}
+	for name, ws := range workspaces {
+		if !ws.exists() {
+			continue
+		}
+		infos = append(infos, models.WorkspaceInfo{Name: name, Mocks: len(ws.configs)})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// writeWorkspaceFile serializa un valor y lo guarda en un archivo del workspace, creando su directorio si hace falta.
+func writeWorkspaceFile(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}